@@ -0,0 +1,53 @@
+package check
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cachingRunner is a GitRunner keeping outputs of git commands on disk, so
+// that analysing the same commits again skips them. Outputs are keyed by the
+// commits analysed, thus invalidated as soon as a point moves
+type cachingRunner struct {
+	runner GitRunner
+	dir    string
+	// commits are the commits of the starting and ending points
+	commits string
+}
+
+// Run returns the cached output of a git command, running it if unknown.
+// Failures aren't cached
+func (r cachingRunner) Run(ctx context.Context, args ...string) (string, error) {
+	sum := sha256.Sum256([]byte(r.commits + "\x00" + strings.Join(args, "\x00")))
+	filename := filepath.Join(r.dir, hex.EncodeToString(sum[:]))
+	if cached, err := os.ReadFile(filename); err == nil {
+		return string(cached), nil
+	}
+
+	output, err := r.runner.Run(ctx, args...)
+	if err != nil {
+		return output, err
+	}
+	// An unwritable cache only costs the next analysis
+	r.store(filename, output)
+
+	return output, nil
+}
+
+// store writes an output in the cache, atomically for concurrent analyses
+// not to read it partially
+func (r cachingRunner) store(filename string, output string) {
+	temporary, err := os.CreateTemp(r.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	_, errWrite := temporary.WriteString(output)
+	errClose := temporary.Close()
+	if errWrite != nil || errClose != nil || os.Rename(temporary.Name(), filename) != nil {
+		os.Remove(temporary.Name())
+	}
+}
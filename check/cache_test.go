@@ -0,0 +1,62 @@
+package check
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "check-break.json"), `{"cache": ".cache"}`)
+	var commands []string
+	runner := fakeRunner{
+		nameStatus: "M\tfoo.go\n",
+		diff:       " package foo\n \n-func Foo(a int) {\n+func Foo() {\n }\n",
+		commands:   &commands,
+	}
+	// diffs runs an analysis, returning git diff commands run
+	diffs := func(end string) []string {
+		commands = nil
+		b, err := InitWithRunner(dir, "start", end, "check-break.json", runner)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, err := b.Analyze()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertExplanations(t, breaksOf(results, "foo.go"), "Deletion of parameter")
+		run := make([]string, 0)
+		for _, command := range commands {
+			if strings.HasPrefix(command, "diff ") {
+				run = append(run, command)
+			}
+		}
+		return run
+	}
+
+	if run := diffs("HEAD"); 2 != len(run) {
+		t.Errorf("Expected git diff to run, got %q", run)
+	}
+	if run := diffs("HEAD"); 0 != len(run) {
+		t.Errorf("Expected cached outputs, got %q", run)
+	}
+	// Another ending point is other commits
+	if run := diffs("main"); 2 != len(run) {
+		t.Errorf("Expected git diff to run, got %q", run)
+	}
+}
+
+func TestCacheSkipsWorkingTree(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "check-break.json"), `{"cache": ".cache"}`)
+
+	b, err := InitWithRunner(dir, "start", WorkingTree, "check-break.json", fakeRunner{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, cached := b.git.(cachingRunner); cached {
+		t.Error("Expected uncommitted changes not to be cached")
+	}
+}
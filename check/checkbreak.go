@@ -1,42 +1,172 @@
 package check
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 )
 
+var (
+	// ErrPathNotFound is matched by errors of Init when the analysed path
+	// isn't a directory
+	ErrPathNotFound = errors.New("Path doesn't exist")
+	// ErrRefNotFound is matched by errors of Init when a point doesn't
+	// resolve to a commit
+	ErrRefNotFound = errors.New("The object doesn't exist")
+)
+
+// PathNotFoundError tells the analysed path isn't a directory
+type PathNotFoundError struct {
+	Path string
+}
+
+func (e *PathNotFoundError) Error() string {
+	return fmt.Sprintf("Path %s doesn't exist", e.Path)
+}
+
+// Is matches ErrPathNotFound
+func (e *PathNotFoundError) Is(target error) bool {
+	return ErrPathNotFound == target
+}
+
+// RefNotFoundError tells a point doesn't resolve to a commit
+type RefNotFoundError struct {
+	Point string
+}
+
+func (e *RefNotFoundError) Error() string {
+	return fmt.Sprintf("The object %s doesn't exist", e.Point)
+}
+
+// Is matches ErrRefNotFound
+func (e *RefNotFoundError) Is(target error) bool {
+	return ErrRefNotFound == target
+}
+
 // Break represents base structure required for evaluating code changes
 type Break struct {
 	workingPath string
 	startPoint  string
 	endPoint    string
 	config      *config
+	git         GitRunner
+	// paths restrict the analysis to some changed files, all if empty
+	paths []string
+	// direct compares both points as is, not from their merge base
+	direct bool
+	// samePoints tells both points are the same commit, nothing changing
+	samePoints bool
+	// fileList are changed files in name-status format, listed from the two
+	// points if nil
+	fileList []string
+	// progress is called once a file is processed, if any
+	progress func(filename string, processed int, total int)
+	// failOn are message IDs of explanations failing the analysis, hard
+	// breaks if empty
+	failOn map[string]bool
 }
 
 // Init bootstraps Break structure
 func Init(workingPath string, startPoint string, endPoint string, configFilename string) (*Break, error) {
-	if errPath := os.Chdir(workingPath); errPath != nil {
-		return nil, fmt.Errorf("Path %s doesn't exist", workingPath)
-	}
-
-	if !refExists(startPoint) {
-		return nil, fmt.Errorf("The object %s doesn't exist", startPoint)
-	}
+	return InitWithRunner(workingPath, startPoint, endPoint, configFilename, execRunner{dir: workingPath})
+}
 
-	if !refExists(endPoint) {
-		return nil, fmt.Errorf("The object %s doesn't exist", endPoint)
+// InitWithRunner bootstraps Break structure, running git commands through
+// runner, which is responsible for running them in workingPath
+func InitWithRunner(workingPath string, startPoint string, endPoint string, configFilename string, runner GitRunner) (*Break, error) {
+	if info, errPath := os.Stat(workingPath); errPath != nil || !info.IsDir() {
+		return nil, &PathNotFoundError{Path: workingPath}
 	}
 
-	return &Break{
+	b := &Break{
 		workingPath: workingPath,
 		startPoint:  startPoint,
 		endPoint:    endPoint,
-		config:      loadConfiguration(workingPath, configFilename),
-	}, nil
+		git:         runner,
+	}
+
+	conf, errConfig := loadConfiguration(workingPath, configFilename)
+	if errConfig != nil {
+		return nil, errConfig
+	}
+	b.config = conf
+
+	startCommit, errStart := b.resolved(startPoint)
+	if errStart != nil {
+		return nil, errStart
+	}
+
+	endCommit := endPoint
+	if isRef(endPoint) {
+		var errEnd error
+		if endCommit, errEnd = b.resolved(endPoint); errEnd != nil {
+			return nil, errEnd
+		}
+		b.samePoints = startCommit == endCommit
+	}
+	if b.HasConfiguration() {
+		if errFailOn := b.FailOn(conf.FailOn); errFailOn != nil {
+			return nil, errFailOn
+		}
+	}
+
+	if b.HasConfiguration() && "" != conf.Cache && isRef(endPoint) {
+		// Uncommitted changes can't be identified, thus cached
+		dir := conf.Cache
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(workingPath, dir)
+		}
+		if errCache := os.MkdirAll(dir, 0755); errCache != nil {
+			return nil, fmt.Errorf("Cache directory %s can't be created : %s", dir, errCache)
+		}
+		b.git = cachingRunner{
+			runner:  runner,
+			dir:     dir,
+			commits: startCommit + ".." + endCommit,
+		}
+	}
+
+	return b, nil
+}
+
+// gitRunner is the GitRunner of b, running the git binary in the working
+// path by default
+func (b *Break) gitRunner() GitRunner {
+	if b.git == nil {
+		return execRunner{dir: b.workingPath}
+	}
+
+	return b.git
+}
+
+// CompareDirectly compares the ending point to the starting point as is
+// (`git diff start..end`), rather than to the merge base of both points
+// (`git diff start...end`), the default. Changes made on the starting point
+// side since the branches diverged are then reported too
+func (b *Break) CompareDirectly() {
+	b.direct = true
+}
+
+// SamePoints tells whether both points resolve to the same commit, in which
+// case there is no change to analyse
+func (b *Break) SamePoints() bool {
+	return b.samePoints
+}
+
+// OnProgress calls progress each time a changed file is processed, with its
+// name and the count of files processed so far out of total, so that long
+// analyses can give feedback. Calls are never concurrent
+func (b *Break) OnProgress(progress func(filename string, processed int, total int)) {
+	b.progress = progress
 }
 
 // HasConfiguration verifies that the config has been loaded
@@ -44,31 +174,68 @@ func (b *Break) HasConfiguration() bool {
 	return b.config != nil
 }
 
-// filter drops a file if it satisfies exclusion criteria
-func (b *Break) filter(files []file) []file {
-	if 0 == len(b.exclusions()) {
-		return files
-	}
+// filter drops a file if it satisfies exclusion criteria, returning dropped
+// ones apart
+func (b *Break) filter(files []file) ([]file, []file) {
 	filtered := make([]file, 0)
-	var toExclude bool
-	excluded := b.config.Excluded.Path
+	excluded := make([]file, 0)
 	for _, f := range files {
-		toExclude = false
-		for _, e := range excluded {
-			if strings.HasPrefix(f.name, e) {
-				toExclude = true
-				break
-			}
-		}
-		if !toExclude {
+		if b.isExcluded(f.name) {
+			f.ignoredReason = "excluded"
+			excluded = append(excluded, f)
+		} else {
 			filtered = append(filtered, f)
 		}
 	}
 
-	return filtered
+	return filtered, excluded
+}
+
+// defaultExclusions are globs of directories never worth analysing (vendored
+// or generated code), unless disabled by config
+var defaultExclusions = []string{"**/vendor/**", "**/node_modules/**", "**/.git/**"}
+
+// isExcluded checks if a path satisfies an exclusion criteria, by prefix or by glob
+func (b *Break) isExcluded(name string) bool {
+	for _, g := range b.globs() {
+		if matchGlob(g, name) {
+			return true
+		}
+	}
+	if !b.HasConfiguration() {
+		return false
+	}
+	for _, e := range b.config.Excluded.Path {
+		if strings.HasPrefix(name, e) {
+			return true
+		}
+	}
+	for _, r := range b.config.excludedRegexes {
+		if r.MatchString(name) {
+			return true
+		}
+	}
+	if o := b.config.override(name); o != nil {
+		return o.excludes(name)
+	}
+
+	return false
+}
+
+// globs are the excluded globs, default ones included
+func (b *Break) globs() []string {
+	globs := make([]string, 0)
+	if !b.HasConfiguration() || !b.config.DisableDefaultExclusions {
+		globs = append(globs, defaultExclusions...)
+	}
+	if b.HasConfiguration() {
+		globs = append(globs, b.config.Excluded.Glob...)
+	}
+
+	return globs
 }
 
-// exclusions is the exclusion list provided by config file
+// exclusions is the exclusion list, default one and provided by config file
 func (b *Break) exclusions() []string {
 	excluded := make([]string, 0)
 	if b.HasConfiguration() {
@@ -76,16 +243,46 @@ func (b *Break) exclusions() []string {
 			excluded = append(excluded, path)
 		}
 	}
+	excluded = append(excluded, b.globs()...)
+	if b.HasConfiguration() {
+		for _, regex := range b.config.Excluded.Regex {
+			excluded = append(excluded, regex)
+		}
+		directories := make([]string, 0, len(b.config.Overrides))
+		for directory := range b.config.Overrides {
+			directories = append(directories, directory)
+		}
+		sort.Strings(directories)
+		for _, directory := range directories {
+			o := b.config.Overrides[directory]
+			for _, criteria := range [][]string{o.Excluded.Path, o.Excluded.Glob, o.Excluded.Regex} {
+				for _, criterion := range criteria {
+					excluded = append(excluded, criterion+" (in "+directory+")")
+				}
+			}
+		}
+	}
 
 	return excluded
 }
 
 // file is a file representation
 type file struct {
-	name     string
-	status   string
-	diff     diff
-	typeFile string
+	name         string
+	previousName string
+	status       string
+	diff         diff
+	typeFile     string
+	// customPattern is the break pattern supplied by config, if any
+	customPattern *regexp.Regexp
+	// requiresDeprecation tells deletions must have been deprecated first
+	requiresDeprecation bool
+	// aliases are type aliases declared in config, if any
+	aliases *aliases
+	// binary tells if git sees the file as binary, thus without any signature
+	binary bool
+	// ignoredReason explains why the file isn't analysed, if so
+	ignoredReason string
 }
 
 // method is a potential break on a public method
@@ -94,6 +291,14 @@ type method struct {
 	after        string
 	commonFactor string
 	explanation  string
+	severity     Severity
+	// message is the explanation translated for reports, explanation if empty
+	message string
+	// failing tells the break fails the analysis
+	failing bool
+	// line is the line of after in the new version, or of before in the old
+	// one if there's no after
+	line int
 }
 
 // breaks returns all potentials CB on a file
@@ -105,14 +310,24 @@ func (f *file) breaks() (*[]method, error) {
 
 	var methods []method
 	var moveOnly bool
+	var overloads map[string]string
+	if hasOverloading(f.typeFile) {
+		overloads = f.overloadPairs(pattern)
+	}
+	renamed := make(map[string]bool)
 	for _, deleted := range f.diff.deletions {
 		var closestAdding string
+		line := f.diff.line("-", deleted)
 		moveOnly = false
-		commonFactor := pattern.FindStringSubmatch(deleted)[0]
+		commonFactor, kind := f.commonFactor(pattern, deleted)
+		if commonFactor == "" {
+			// Not a signature, nothing to compare with
+			continue
+		}
 		for _, added := range f.diff.addings {
-			if strings.HasPrefix(added, commonFactor) {
-				// It's only a move
-				if len(strings.Split(deleted, " ")) == len(strings.Split(added, " ")) && len(deleted) == len(added) {
+			if f.isSameDeclaration(pattern, added, commonFactor, kind) {
+				// It's only a move, types aliased and modifiers order aside
+				if compacted(f.resolved(f.ordered(deleted), commonFactor)) == compacted(f.resolved(f.ordered(added), commonFactor)) {
 					moveOnly = true
 					break
 				} else {
@@ -120,261 +335,2649 @@ func (f *file) breaks() (*[]method, error) {
 				}
 			}
 		}
+		if overloads != nil && !moveOnly {
+			closestAdding = overloads[deleted]
+		}
 
-		explanation := explainedChanges(deleted, closestAdding)
+		explanation := explainedDeclarationChanges(f.resolved(deleted, commonFactor), f.resolved(closestAdding, commonFactor), kind, f.typeFile)
+		if !moveOnly && closestAdding == "" {
+			if hidden := f.hiddenAdding(commonFactor); hidden != "" {
+				closestAdding = hidden
+				explanation = reducedVisibility(deleted, hidden, f.typeFile)
+			} else if conversion := f.convertedAdding(pattern, deleted, commonFactor, kind, renamed); conversion != "" {
+				renamed[conversion] = true
+				closestAdding = conversion
+				explanation = "Method converted to function"
+				if "" == goReceiverType(deleted) {
+					explanation = "Function converted to method"
+				}
+			} else if renaming := f.renamedAdding(pattern, deleted, commonFactor, kind, renamed); renaming != "" {
+				renamed[renaming] = true
+				closestAdding = renaming
+				explanation = "Method renamed"
+			} else if "Deletion of method" == explanation && overloads != nil && f.hasOverload(pattern, commonFactor, kind) {
+				explanation = "Deletion of overload"
+			} else if "Deletion of method" == explanation && f.requiresDeprecation && !f.diff.deprecated[deleted] {
+				explanation = "Removed without prior deprecation"
+			}
+		}
+		if closestAdding != "" {
+			line = f.diff.line("+", closestAdding)
+		}
 		if !moveOnly && explanation != "" {
 			method := method{
 				before:       deleted,
 				after:        closestAdding,
 				commonFactor: commonFactor,
 				explanation:  explanation,
+				severity:     severityOf(explanation, f.typeFile),
+				line:         line,
 			}
 			methods = append(methods, method)
 		}
 	}
+	methods = append(methods, f.abstractAdded()...)
 
 	return &methods, nil
 }
 
-// files initializes files struct
-func files(changedFiles []string, b Break) ([]file, []file) {
-	supported := make([]file, 0)
-	ignored := make([]file, 0)
-
-	for _, fileLine := range changedFiles {
-		f := file{}
-		status, name, filetype := extractDataFile(fileLine)
-		f.name = name
-		f.status = status
-		f.typeFile = filetype
-		diff, err := f.getDiff(b.startPoint, b.endPoint)
-		if err == nil {
-			f.diff = *diff
-		}
-
-		if f.canHaveBreak() {
-			if f.isTypeSupported() {
-				supported = append(supported, f)
-			} else {
-				ignored = append(ignored, f)
-			}
+// abstractAdded returns members added to existing abstract declarations, so
+// that implementers have to implement them. Changed members aren't added
+func (f *file) abstractAdded() []method {
+	existing := make(map[string]bool)
+	for _, deleted := range f.diff.abstractDeletions {
+		existing[methodName(f.abstractFactor(deleted))] = true
+	}
+	var methods []method
+	for _, added := range f.diff.abstractAddings {
+		factor := f.abstractFactor(added)
+		if existing[methodName(factor)] {
+			continue
 		}
+		methods = append(methods, method{
+			after:        added,
+			commonFactor: factor,
+			explanation:  "Method added to interface",
+			severity:     severityOf("Method added to interface", f.typeFile),
+			line:         f.diff.line("+", added),
+		})
 	}
 
-	return supported, ignored
-}
-
-func (f *file) canHaveBreak() bool {
-	return "A" != f.status
+	return methods
 }
 
-// explainedChanges try to understand nature of changes, returning a reason
-// for compatibility break
-func explainedChanges(before string, after string) string {
-	if after == "" {
-		return "Deletion of method"
+// overloadPairs pairs deleted declarations with the added ones declaring the
+// same thing and closest by parameters, each adding being paired once, so
+// that changing an overload doesn't pair another one. Moves aren't paired
+func (f *file) overloadPairs(pattern *regexp.Regexp) map[string]string {
+	type candidate struct {
+		deleted string
+		added   string
+		// common is the count of parameters kept, gap the difference of counts
+		common int
+		gap    int
 	}
 
-	deleted, added := differences(strings.Split(before, ","), strings.Split(after, ","))
-	if len(deleted) > len(added) {
-		if hasDefaultParameter(deleted) && !hasDefaultParameter(added) {
-			return "Deletion of default parameter"
-		}
-		return "Deletion of parameter"
-	} else if len(deleted) < len(added) {
-		var explanation string
-		for i := 0; i < len(added); i++ {
-			if !hasDefaultParameter(added) {
-				explanation = "Adding a parameter without default value"
+	moved := make(map[string]bool)
+	for _, deleted := range f.diff.deletions {
+		for _, added := range f.diff.addings {
+			if compacted(deleted) == compacted(added) {
+				moved[deleted], moved[added] = true, true
 			}
 		}
-		return explanation
-	} else {
-		explanation := "Unknown signature change"
-		for i := 0; i < len(deleted); i++ {
-			if !hasDefaultParameter(added) {
-				if hasDefaultParameter(deleted) {
-					return "Deletion of default parameter"
-				}
-				return "Adding a parameter without default value"
+	}
+	candidates := make([]candidate, 0)
+	for _, deleted := range f.diff.deletions {
+		commonFactor, kind := f.commonFactor(pattern, deleted)
+		if commonFactor == "" || moved[deleted] {
+			continue
+		}
+		parametersBefore := signatureParameters(deleted, f.typeFile)
+		for _, added := range f.diff.addings {
+			if moved[added] || !f.isSameDeclaration(pattern, added, commonFactor, kind) {
+				continue
+			}
+			parametersAfter := signatureParameters(added, f.typeFile)
+			removed, _ := differences(parametersBefore, parametersAfter)
+			gap := len(parametersBefore) - len(parametersAfter)
+			if gap < 0 {
+				gap = -gap
 			}
-			// TODO : Precise cases :
-			//	- add type
-			//	- change type
-			// 	- drop type (not a CB)
+			candidates = append(candidates, candidate{deleted, added, len(parametersBefore) - len(removed), gap})
 		}
-		return explanation
 	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].common != candidates[j].common {
+			return candidates[i].common > candidates[j].common
+		}
+		return candidates[i].gap < candidates[j].gap
+	})
+
+	pairs := make(map[string]string)
+	paired := make(map[string]bool)
+	for _, c := range candidates {
+		if _, known := pairs[c.deleted]; known || paired[c.added] {
+			continue
+		}
+		pairs[c.deleted] = c.added
+		paired[c.added] = true
+	}
+
+	return pairs
 }
 
-func hasDefaultParameter(slice []string) bool {
-	for _, s := range slice {
-		if strings.Contains(s, "=") {
-			return true
+// hasOverload tells if another declaration of the same name as a common
+// factor remains, unchanged or added
+func (f *file) hasOverload(pattern *regexp.Regexp, commonFactor string, kind string) bool {
+	for _, declarations := range [][]string{f.diff.unchanged, f.diff.addings} {
+		for _, declaration := range declarations {
+			if f.isSameDeclaration(pattern, declaration, commonFactor, kind) {
+				return true
+			}
 		}
 	}
 
 	return false
 }
 
-// differences shows slices of differences (deletion, adding) between two slices
-func differences(before []string, after []string) ([]string, []string) {
-	var length int
-	lengthBefore := len(before)
-	lengthAfter := len(after)
+// isSameDeclaration tells if an adding declares the same thing as a common
+// factor. Types are identified by their name only, as modifiers may change
+// (`public final class Foo` -> `public class Foo`)
+func (f *file) isSameDeclaration(pattern *regexp.Regexp, added string, commonFactor string, kind string) bool {
+	if typeKind == kind || constantKind == kind || enumValueKind == kind {
+		addedFactor, addedKind := f.commonFactor(pattern, added)
+		return kind == addedKind && typeName(addedFactor) == typeName(commonFactor)
+	}
+
+	return strings.HasPrefix(f.normalized(added), f.normalized(commonFactor))
+}
+
+// typeName extracts the name ending the factor of a type declaration
+func typeName(factor string) string {
+	fields := strings.Fields(strings.TrimRight(factor, " ["))
+	if 0 == len(fields) {
+		return ""
+	}
+
+	return fields[len(fields)-1]
+}
+
+// compacted drops whitespace not separating words, so that reindenting or
+// reformatting a signature doesn't change it
+func compacted(signature string) string {
+	signature = strings.Join(strings.Fields(signature), " ")
 
-	if lengthBefore < lengthAfter {
-		for i := lengthBefore; i < lengthAfter; i++ {
-			before = append(before, "")
+	return punctuationSpacePattern.ReplaceAllString(signature, "$1")
+}
+
+// punctuationSpacePattern matches punctuation with its surrounding spaces
+var punctuationSpacePattern = regexp.MustCompile(` ?([(),\[\]{}<>:;=]) ?`)
+
+// hiddenAdding returns the added non public declaration having the same name
+// as a common factor, if any
+func (f *file) hiddenAdding(commonFactor string) string {
+	name := methodName(commonFactor)
+	hiddenPattern := f.hiddenPattern()
+	for _, hidden := range f.diff.hidden {
+		if factor := matchedFactor(hiddenPattern, hidden); factor != "" && methodName(factor) == name {
+			return hidden
 		}
-		length = lengthAfter
-	} else {
-		for i := lengthAfter; i < lengthBefore; i++ {
-			after = append(after, "")
+	}
+
+	return ""
+}
+
+// renameSimilarity is the minimal similarity of names for a method to be
+// considered as renamed
+const renameSimilarity = 0.5
+
+// renamedAdding returns the added method which deleted has been renamed into,
+// if any: a new method with the same parameters and return type, and a close
+// enough name. Additions already paired are skipped
+func (f *file) renamedAdding(pattern *regexp.Regexp, deleted string, commonFactor string, kind string, paired map[string]bool) string {
+	if methodKind != kind {
+		return ""
+	}
+	name := methodName(commonFactor)
+	renaming := ""
+	bestSimilarity := renameSimilarity
+	for _, added := range f.diff.addings {
+		addedFactor, addedKind := f.commonFactor(pattern, added)
+		addedName := methodName(addedFactor)
+		if paired[added] || methodKind != addedKind || "" == addedName || name == addedName || f.isModification(pattern, addedFactor) {
+			continue
+		}
+		if strings.Join(signatureParameters(deleted, f.typeFile), ",") != strings.Join(signatureParameters(added, f.typeFile), ",") ||
+			returnType(deleted, f.typeFile) != returnType(added, f.typeFile) {
+			continue
+		}
+		if similarity := nameSimilarity(name, addedName); similarity >= bestSimilarity {
+			renaming, bestSimilarity = added, similarity
 		}
-		length = lengthBefore
 	}
 
-	var deleted []string
-	var added []string
-	for i := 0; i < length; i++ {
-		if before[i] == after[i] {
+	return renaming
+}
+
+// convertedAdding returns the added go method which the deleted function has
+// been converted into, or the added function for a deleted method, if any:
+// the same name, with a receiver on one side only. Additions already paired
+// are skipped
+func (f *file) convertedAdding(pattern *regexp.Regexp, deleted string, commonFactor string, kind string, paired map[string]bool) string {
+	if "go" != f.typeFile || methodKind != kind {
+		return ""
+	}
+	name := methodName(commonFactor)
+	isFunction := "" == goReceiverType(deleted)
+	for _, added := range f.diff.addings {
+		addedFactor, addedKind := f.commonFactor(pattern, added)
+		if paired[added] || methodKind != addedKind || name != methodName(addedFactor) || f.isModification(pattern, addedFactor) {
 			continue
 		}
-		if before[i] == "" {
-			added = append(added, after[i])
-		} else if after[i] == "" {
-			deleted = append(deleted, before[i])
-		} else {
-			deleted = append(deleted, before[i])
-			added = append(added, after[i])
+		if isFunction != ("" == goReceiverType(added)) {
+			return added
 		}
 	}
 
-	return deleted, added
+	return ""
 }
 
-// extractDataFile gives file's name, status and type
-func extractDataFile(fileLine string) (string, string, string) {
-	fields := strings.Fields(fileLine)
-	status := fields[0]
-	name := fields[1]
-	if strings.HasPrefix(status, "R") {
-		status = "D"
+// isModification tells if a common factor of an adding matches a deletion,
+// the adding being the new version of an existing method
+func (f *file) isModification(pattern *regexp.Regexp, addedFactor string) bool {
+	for _, deleted := range f.diff.deletions {
+		if factor, _ := f.commonFactor(pattern, deleted); f.normalized(factor) == f.normalized(addedFactor) {
+			return true
+		}
 	}
 
-	return status, name, typefile(name)
+	return false
 }
 
-// typeFile return a file's extension
-func typefile(filepath string) string {
-	var typeFile string
-	filename := path.Base(filepath)
-	if strings.Contains(filename, ".") && !strings.HasPrefix(filename, ".") {
-		typeFile = strings.TrimSpace(path.Ext(filename)[1:])
+// nameSimilarity is the ratio of characters two names have in common, in the
+// same order, from 0 (unrelated) to 1 (identical)
+func nameSimilarity(before string, after string) float64 {
+	charsBefore := strings.Split(before, "")
+	charsAfter := strings.Split(after, "")
+	if 0 == len(charsBefore)+len(charsAfter) {
+		return 1
 	}
+	deleted, _ := differences(charsBefore, charsAfter)
+	common := len(charsBefore) - len(deleted)
 
-	return typeFile
+	return float64(2*common) / float64(len(charsBefore)+len(charsAfter))
 }
 
-// diff represents the diff of a file, segregated with deletion and adding
-type diff struct {
-	deletions []string
-	addings   []string
+// resolved replaces type aliases of a declaration identified by a common
+// factor, its name aside
+func (f *file) resolved(declaration string, commonFactor string) string {
+	return f.aliases.resolved(declaration, declaredName(commonFactor), f.typeFile)
 }
 
-// getDiff fetches diff (in a git sense) and extracts changes occured
-func (f *file) getDiff(startObject string, endObject string) (*diff, error) {
-	if f.isDeleted() {
-		return f.getDiffDeleted(startObject)
+// declaredNamePattern matches the name ending a common factor of a non
+// method declaration (`type Foo `)
+var declaredNamePattern = regexp.MustCompile(`([A-Za-z0-9_$]+)[^A-Za-z0-9_$]*$`)
+
+// declaredName extracts the name of a declaration from its common factor
+func declaredName(commonFactor string) string {
+	if name := methodName(commonFactor); name != "" {
+		return name
 	}
-	diffFile, err := diffFile(startObject, endObject, f.name)
-	if err != nil {
-		return nil, err
+	matches := declaredNamePattern.FindStringSubmatch(commonFactor)
+	if matches == nil {
+		return ""
 	}
 
-	pattern, errPattern := f.breakPattern()
-	if errPattern != nil {
-		return nil, errPattern
+	return matches[1]
+}
+
+// methodNamePattern matches the name ending a common factor (`public function foo(`)
+var methodNamePattern = regexp.MustCompile(`([A-Za-z0-9_$]+)(<[^(]*>|\[[^(]*\])?[?!]?\($`)
+
+// methodName extracts the name of a method from its common factor
+func methodName(commonFactor string) string {
+	matches := methodNamePattern.FindStringSubmatch(commonFactor)
+	if matches == nil {
+		return ""
 	}
 
-	var diffDeleted []string
-	var diffAdded []string
-	for _, line := range diffFile {
-		if strings.HasPrefix(line, "-") {
-			diffDeleted = append(diffDeleted, strings.TrimSpace(line[1:]))
-		} else if strings.HasPrefix(line, "+") {
-			diffAdded = append(diffAdded, strings.TrimSpace(line[1:]))
+	return matches[1]
+}
+
+// Kinds of declaration, driving the explanation of their changes
+const (
+	methodKind    = "method"
+	fieldKind     = "field"
+	typeKind      = "type"
+	constantKind  = "constant"
+	enumValueKind = "enum value"
+)
+
+// commonFactor returns the part of a declaration identifying it and its
+// kind, empty if the line isn't a declaration
+func (f *file) commonFactor(pattern *regexp.Regexp, line string) (string, string) {
+	if factor := matchedFactor(pattern, line); factor != "" {
+		return factor, methodKind
+	}
+	if typePattern := f.typePattern(); typePattern != nil {
+		if factor := matchedFactor(typePattern, line); factor != "" {
+			return factor, typeKind
+		}
+	}
+	if constantPattern := f.constantPattern(); constantPattern != nil {
+		if factor := matchedFactor(constantPattern, line); factor != "" {
+			return factor, constantKind
 		}
 	}
 
-	return &diff{
-		deletions: filteredByPattern(pattern, diffDeleted),
-		addings:   filteredByPattern(pattern, diffAdded),
-	}, nil
+	return f.memberFactor(line)
 }
 
-func (f *file) isDeleted() bool {
-	return "D" == f.status
+// matchedFactor returns the part of a line matched by pattern. A pattern may
+// need to look past the factor (`greet(name) {`), capturing it as `factor`
+func matchedFactor(pattern *regexp.Regexp, line string) string {
+	matches := pattern.FindStringSubmatch(line)
+	if matches == nil {
+		return ""
+	}
+	if i := pattern.SubexpIndex("factor"); i > 0 && matches[i] != "" {
+		return matches[i]
+	}
+
+	return matches[0]
 }
 
-func (f *file) getDiffDeleted(startObject string) (*diff, error) {
-	diffFile, err := showFile(startObject, f.name)
-	if err != nil {
-		return nil, err
+// normalized prepares a signature for pairing, dropping what doesn't
+// identify a method (go type parameters)
+func (f *file) normalized(signature string) string {
+	if "go" == f.typeFile {
+		if receiver := goReceiverType(signature); receiver != "" {
+			signature = goReceiverPattern.ReplaceAllString(signature, "func ("+strings.TrimPrefix(receiver, "*")+") ")
+		}
+		return goTypeParametersPattern.ReplaceAllString(signature, "$1(")
+	}
+	if "java" == f.typeFile {
+		// The return type is part of the signature, but not of the identity
+		signature = inheritanceModifierPattern.ReplaceAllString(signature, "")
+		signature = accessModifierPattern.ReplaceAllString(signature, "")
+		if "" != javaReturnType(signature) {
+			signature = javaReturnTypePattern.ReplaceAllString(signature, "$1$7")
+		}
+		return signature
+	}
+	if "kt" == f.typeFile {
+		signature = accessModifierPattern.ReplaceAllString(signature, "")
+		return inheritanceModifierPattern.ReplaceAllString(signature, "")
+	}
+	if "php" == f.typeFile {
+		// Modifiers may come in any order
+		return phpModifierPattern.ReplaceAllString(f.ordered(signature), "")
+	}
+	if "rb" == f.typeFile {
+		// Without parameters, parenthesis may be omitted
+		return rubyBareMethodPattern.ReplaceAllString(signature, "$1(")
+	}
+	if "cs" == f.typeFile {
+		// Neither modifiers nor the return type identify a method
+		return csReturnTypePattern.ReplaceAllString(signature, "$2$6")
 	}
 
-	pattern, errPattern := f.breakPattern()
-	if errPattern != nil {
-		return nil, errPattern
+	return signature
+}
+
+// rubyBareMethodPattern matches a ruby method declared without parameters
+// nor parenthesis (`def foo`)
+var rubyBareMethodPattern = regexp.MustCompile(`^((\s)*def (self\.)?[A-Za-z_][A-Za-z0-9_]*[?!=]?)$`)
+
+// phpModifierPattern matches php modifiers not identifying a method
+var phpModifierPattern = regexp.MustCompile(`\b(abstract|final) `)
+
+// phpModifiersPattern matches the modifiers of a php method
+var phpModifiersPattern = regexp.MustCompile(`^(\s*)((abstract|final|public|protected|private|static) )+function `)
+
+// ordered sorts modifiers of a php declaration, as they may come in any order
+// (`abstract public function` is `public abstract function`)
+func (f *file) ordered(declaration string) string {
+	if "php" != f.typeFile {
+		return declaration
 	}
-	var diffDeleted []string
-	for _, line := range diffFile {
-		diffDeleted = append(diffDeleted, strings.TrimSpace(line))
+	matches := phpModifiersPattern.FindStringSubmatch(declaration)
+	if matches == nil {
+		return declaration
 	}
+	modifiers := strings.Fields(strings.TrimSuffix(strings.TrimPrefix(matches[0], matches[1]), "function "))
+	sort.Strings(modifiers)
 
-	return &diff{
-		deletions: filteredByPattern(pattern, diffDeleted),
-	}, nil
+	return matches[1] + strings.Join(modifiers, " ") + " function " + declaration[len(matches[0]):]
 }
 
-// filteredByPattern keeps only data lines that match a pattern
-func filteredByPattern(r *regexp.Regexp, data []string) []string {
-	filtered := make([]string, 0)
-	for _, element := range data {
-		if r.MatchString(element) {
-			filtered = append(filtered, element)
-		}
+// javaReturnTypePattern matches a java method declaration up to its name,
+// its return type being apart
+var javaReturnTypePattern = regexp.MustCompile(`^((\s)*((public|protected|private|static|final|abstract|synchronized|native|default|strictfp) )*)(<[^(]*> )?(.+?) ([A-Za-z_][A-Za-z0-9_]*\()`)
+
+// javaModifiers are the modifiers of a java method declaration
+var javaModifiers = map[string]bool{
+	"public": true, "protected": true, "private": true, "static": true, "final": true, "abstract": true,
+	"synchronized": true, "native": true, "default": true, "strictfp": true,
+}
+
+// javaReturnType extracts the return type of a java method declaration,
+// empty for a constructor
+func javaReturnType(signature string) string {
+	matches := javaReturnTypePattern.FindStringSubmatch(signature)
+	if matches == nil || javaModifiers[matches[6]] {
+		return ""
 	}
 
-	return filtered
+	return matches[6]
 }
 
-func (f *file) isTypeSupported() bool {
-	_, err := f.breakPattern()
+// csReturnTypePattern matches a c# method declaration up to its name, its
+// return type being apart
+var csReturnTypePattern = regexp.MustCompile(`^((\s)*((public|protected|private|internal|static|virtual|override|abstract|sealed|async|new|extern|unsafe|partial|readonly) )*)(.+?) ([A-Za-z_][A-Za-z0-9_]*(<[^(]+>)?\()`)
 
-	return err == nil
+// csModifiers are the modifiers of a c# method declaration
+var csModifiers = map[string]bool{
+	"public": true, "protected": true, "private": true, "internal": true, "static": true, "virtual": true, "override": true,
+	"abstract": true, "sealed": true, "async": true, "new": true, "extern": true, "unsafe": true, "partial": true, "readonly": true,
 }
 
-// breakPattern returns the regex of a potential compatibility break associated
-// with type of the file
-func (f *file) breakPattern() (*regexp.Regexp, error) {
-	var pattern *regexp.Regexp
-	switch f.typeFile {
-	case "go":
-		pattern = regexp.MustCompile(`^(\s)*func( \(.+)\)? [A-Z]{1}[A-Za-z]*\(`)
-	case "php":
-		pattern = regexp.MustCompile(`^(\s)*public( static)? function [_A-Za-z]+\(|^(\s)*function [_A-Za-z]+\(`)
+// csReturnType extracts the return type of a c# method declaration, empty for
+// a constructor
+func csReturnType(signature string) string {
+	matches := csReturnTypePattern.FindStringSubmatch(signature)
+	if matches == nil || csModifiers[matches[5]] {
+		return ""
+	}
+
+	return matches[5]
+}
+
+// inheritanceModifierPattern matches modifiers allowing or forbidding to
+// override a declaration
+var inheritanceModifierPattern = regexp.MustCompile(`\b(non-sealed|final|sealed|open) `)
+
+// permitsPattern matches the permits clause of a java sealed declaration
+var permitsPattern = regexp.MustCompile(`\s+permits [^{]+`)
+
+// onlyInheritanceChanged tells if java or kotlin declarations only differ by
+// their inheritance modifiers, the subclasses a sealed one permits included
+func onlyInheritanceChanged(before string, after string, typeFile string) bool {
+	if "java" != typeFile && "kt" != typeFile {
+		return false
+	}
+	stripped := func(declaration string) string {
+		return compacted(inheritanceModifierPattern.ReplaceAllString(permitsPattern.ReplaceAllString(declaration, " "), ""))
+	}
+
+	return stripped(before) == stripped(after)
+}
+
+// madeFinal tells if a java or kotlin declaration can't be overridden or
+// extended anymore, nothing else having changed
+func madeFinal(before string, after string, typeFile string) bool {
+	if !onlyInheritanceChanged(before, after, typeFile) {
+		return false
+	}
+
+	return isOverridable(before, typeFile) && !isOverridable(after, typeFile)
+}
+
+// isOverridable tells if a java or kotlin declaration can be overridden or
+// extended, from its modifiers. Kotlin declarations are final by default
+func isOverridable(declaration string, typeFile string) bool {
+	modifiers := make(map[string]bool)
+	for _, modifier := range strings.Fields(strings.SplitN(declaration, "(", 2)[0]) {
+		modifiers[modifier] = true
+	}
+	if "java" == typeFile {
+		return !modifiers["final"] && !modifiers["sealed"]
+	}
+
+	return modifiers["open"] || modifiers["abstract"] || (modifiers["override"] && !modifiers["final"])
+}
+
+// accessModifierPattern matches access modifiers of java and kotlin
+// declarations
+var accessModifierPattern = regexp.MustCompile(`\b(public|protected|internal|private) `)
+
+// visibilities rank access modifiers, from the most hidden one
+var visibilities = map[string]int{"private": 0, "package-private": 1, "internal": 1, "protected": 2, "public": 3}
+
+// visibility returns the access modifier of a declaration, the default one of
+// its langage if none
+func visibility(declaration string, typeFile string) string {
+	if modifier := accessModifierPattern.FindStringSubmatch(strings.SplitN(declaration, "(", 2)[0]); modifier != nil {
+		return modifier[1]
+	}
+	switch typeFile {
 	case "java":
-		pattern = regexp.MustCompile(`^(\s)*public( static)?( .+)? [A-Za-z]+\(`)
-	case "js":
-		pattern = regexp.MustCompile(`^(\s)*function [A-Za-z]+\(|^(\s)*(var )?[A-Za-z._]+(\s)*=(\s)*function \(|(\s)*[A-Za-z._]+(\s)*:(\s)*function \(`)
-	case "sh":
-		pattern = regexp.MustCompile(`^(\s)*function [A-Za-z_]+\(`)
+		return "package-private"
+	case "cs":
+		return "private"
 	}
 
-	if pattern == nil {
-		return pattern, errors.New("Unknown langage")
+	return "public"
+}
+
+// visibilityRank ranks the visibility of a java, kotlin, php or c# declaration,
+// -1 for other langages
+func visibilityRank(declaration string, typeFile string) int {
+	switch typeFile {
+	case "java", "kt", "php", "cs":
+		return visibilities[visibility(declaration, typeFile)]
+	}
+
+	return -1
+}
+
+// onlyVisibilityWidened tells if a declaration became more visible, nothing
+// else having changed
+func onlyVisibilityWidened(before string, after string, typeFile string) bool {
+	if visibilityRank(after, typeFile) <= visibilityRank(before, typeFile) {
+		return false
+	}
+
+	return compacted(accessModifierPattern.ReplaceAllString(before, "")) == compacted(accessModifierPattern.ReplaceAllString(after, ""))
+}
+
+// reducedVisibility explains a visibility reduction, with its transition if
+// known (`public -> protected`)
+func reducedVisibility(before string, after string, typeFile string) string {
+	if visibilityRank(after, typeFile) >= visibilityRank(before, typeFile) {
+		return "Reduced visibility"
+	}
+
+	return fmt.Sprintf("Reduced visibility (%s -> %s)", visibility(before, typeFile), visibility(after, typeFile))
+}
+
+// goTypeParametersPattern matches a go signature up to its type parameters
+var goTypeParametersPattern = regexp.MustCompile(`^((\s)*func( \(.+\))? [A-Za-z0-9_]+)(\[[^(]+\])\(`)
+
+// files initializes files struct, fetching their diff concurrently while
+// keeping the order of changedFiles. It stops as soon as ctx is done
+func files(ctx context.Context, changedFiles []string, b Break) ([]file, []file, error) {
+	fetched := make([]file, len(changedFiles))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	var progressing sync.Mutex
+	processed := 0
+	for w := 0; w < b.workers(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fetched[i] = newFile(ctx, changedFiles[i], b)
+				if b.progress != nil {
+					progressing.Lock()
+					processed++
+					b.progress(fetched[i].name, processed, len(changedFiles))
+					progressing.Unlock()
+				}
+			}
+		}()
+	}
+feeding:
+	for i := range changedFiles {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feeding
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	supported := make([]file, 0)
+	ignored := make([]file, 0)
+	for _, f := range fetched {
+		if !f.canHaveBreak() {
+			continue
+		}
+		switch {
+		case f.binary:
+			f.ignoredReason = "binary file"
+		case !f.isTypeSupported():
+			f.ignoredReason = "unsupported extension"
+		case !b.config.allows(f.typeFile):
+			f.ignoredReason = "language not selected"
+		case f.isTest() && !b.config.includesTests():
+			f.ignoredReason = "test file"
+		}
+		if "" == f.ignoredReason {
+			supported = append(supported, f)
+		} else {
+			ignored = append(ignored, f)
+		}
+	}
+
+	return supported, ignored, nil
+}
+
+// newFile initializes a file struct from a changed file line, with its diff
+func newFile(ctx context.Context, fileLine string, b Break) file {
+	f := file{}
+	status, name, previousName, filetype := extractDataFile(fileLine)
+	f.name = name
+	f.previousName = previousName
+	f.status = status
+	f.typeFile = filetype
+	if !f.canHaveBreak() {
+		// Nothing to compare with
+		return f
+	}
+	if "" == f.typeFile {
+		f.typeFile = f.scriptType(ctx, b)
+	}
+	f.customPattern = b.config.pattern(f.name, f.typeFile)
+	f.requiresDeprecation = b.config.requiresDeprecation()
+	f.aliases = b.config.typeAliases()
+	diff, err := f.getDiff(ctx, b)
+	if err == nil {
+		f.diff = *diff
+	}
+
+	return f
+}
+
+// scriptType guesses the type of an extensionless file from its shebang
+func (f *file) scriptType(ctx context.Context, b Break) string {
+	point := b.endPoint
+	if f.isDeleted() {
+		base, err := b.basePoint(ctx)
+		if err != nil {
+			return ""
+		}
+		point = base
+	}
+	content, err := b.showFile(ctx, point, f.name)
+	if err != nil || 0 == len(content) {
+		return ""
+	}
+
+	return shebangType(content[0])
+}
+
+// shebangType returns the type associated with the interpreter of a shebang
+// (`#!/bin/bash`, `#!/usr/bin/env python3`)
+func shebangType(line string) string {
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+	fields := strings.Fields(line[2:])
+	if 0 == len(fields) {
+		return ""
+	}
+	interpreter := path.Base(fields[0])
+	if "env" == interpreter {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = field
+				break
+			}
+		}
+	}
+
+	switch {
+	case interpreter == "sh" || interpreter == "bash" || interpreter == "dash" || interpreter == "ksh" || interpreter == "zsh":
+		return "sh"
+	case strings.HasPrefix(interpreter, "python"):
+		return "py"
+	case strings.HasPrefix(interpreter, "ruby"):
+		return "rb"
+	case strings.HasPrefix(interpreter, "php"):
+		return "php"
+	case interpreter == "node":
+		return "js"
+	}
+
+	return ""
+}
+
+// workers is the number of files processed at the same time
+func (b *Break) workers() int {
+	if b.HasConfiguration() && b.config.Workers > 0 {
+		return b.config.Workers
+	}
+
+	return runtime.NumCPU()
+}
+
+// canHaveBreak tells if a file had an API to break: modified, renamed,
+// deleted or changed of type. Added and copied files don't, nor unmerged
+// ones. Files added then deleted within the range aren't even listed by git
+func (f *file) canHaveBreak() bool {
+	switch f.status {
+	case "M", "R", "D", "T":
+		return true
+	}
+
+	return false
+}
+
+// explainedDeclarationChanges explains changes according to the kind of the
+// declaration changed
+func explainedDeclarationChanges(before string, after string, kind string, typeFile string) string {
+	if after != "" && madeFinal(before, after, typeFile) {
+		return "Made final/non-overridable"
+	}
+	if after != "" && onlyInheritanceChanged(before, after, typeFile) {
+		// Inheritance loosened, existing callers and subclasses are unaffected
+		return ""
+	}
+	if after != "" && visibilityRank(after, typeFile) < visibilityRank(before, typeFile) {
+		return reducedVisibility(before, after, typeFile)
+	}
+	if after != "" && onlyVisibilityWidened(before, after, typeFile) {
+		// Existing callers are unaffected
+		return ""
+	}
+	switch kind {
+	case fieldKind:
+		if after == "" {
+			return "Deletion of field"
+		}
+		// Name Type `tag`, alignment may have changed
+		if declarationToken(before, 1) != declarationToken(after, 1) {
+			return "Field type changed"
+		}
+		return ""
+	case enumValueKind:
+		if after == "" {
+			return "Enum value removed"
+		}
+		return ""
+	case constantKind:
+		if after == "" {
+			return "Public constant removed"
+		}
+		// Type or value changed
+		if compacted(before) != compacted(after) {
+			return "Public constant changed"
+		}
+		return ""
+	case typeKind:
+		if after == "" {
+			return "Public type removed"
+		}
+		// type Name Definition
+		if "go" == typeFile && declarationToken(before, 2) != declarationToken(after, 2) {
+			return "Type definition changed"
+		}
+		return ""
+	}
+
+	return explainedChanges(before, after, typeFile)
+}
+
+// mutabilityChange explains a method requiring more from its receiver: a rust
+// one borrowing it mutably or taking it (`&self -> &mut self`), a c++ one
+// dropping its trailing const
+func mutabilityChange(before string, after string, typeFile string) string {
+	switch typeFile {
+	case "rs":
+		receiverBefore, receiverAfter := rustReceiver(before), rustReceiver(after)
+		if "" != receiverBefore && "" != receiverAfter && rustReceivers[receiverAfter] > rustReceivers[receiverBefore] {
+			return fmt.Sprintf("Mutability contract changed (%s -> %s)", receiverBefore, receiverAfter)
+		}
+	case "cpp", "cc", "cxx", "h", "hh", "hpp", "hxx":
+		if cppConstMethodPattern.MatchString(before) && !cppConstMethodPattern.MatchString(after) {
+			return "Mutability contract changed (const dropped)"
+		}
+	}
+
+	return ""
+}
+
+// rustReceivers rank receivers of rust methods, from the least demanding one
+var rustReceivers = map[string]int{"&self": 0, "&mut self": 1, "self": 2}
+
+// rustLifetimePattern matches the lifetime of a reference
+var rustLifetimePattern = regexp.MustCompile(`&'[A-Za-z_]+ `)
+
+// rustReceiver returns the receiver of a rust method (`&self`, `&mut self`,
+// `self`), empty for an associated function
+func rustReceiver(signature string) string {
+	parameters := signatureParameters(signature, "rs")
+	if 0 == len(parameters) {
+		return ""
+	}
+	receiver := compacted(rustLifetimePattern.ReplaceAllString(parameters[0], "&"))
+	switch receiver {
+	case "&self", "self:&Self":
+		return "&self"
+	case "&mut self", "self:&mut Self":
+		return "&mut self"
+	case "self", "mut self", "self:Self", "mut self:Self":
+		return "self"
+	}
+
+	return ""
+}
+
+// cppConstMethodPattern matches a c++ member function callable on const objects
+var cppConstMethodPattern = regexp.MustCompile(`\)(\s)*const\b`)
+
+// madeConst tells if a c++ member function gained a trailing const, nothing
+// else having changed
+func madeConst(before string, after string, typeFile string) bool {
+	switch typeFile {
+	case "cpp", "cc", "cxx", "h", "hh", "hpp", "hxx":
+		return !cppConstMethodPattern.MatchString(before) && cppConstMethodPattern.MatchString(after) &&
+			compacted(before) == compacted(cppConstMethodPattern.ReplaceAllString(after, ")"))
+	}
+
+	return false
+}
+
+// declarationToken returns the token at position i of a declaration, empty if none
+func declarationToken(declaration string, i int) string {
+	tokens := strings.Fields(declaration)
+	if i >= len(tokens) {
+		return ""
+	}
+
+	return tokens[i]
+}
+
+// explainedChanges try to understand nature of changes, returning a reason
+// for compatibility break
+func explainedChanges(before string, after string, typeFile string) string {
+	if after == "" {
+		return "Deletion of method"
+	}
+	if explanation := mutabilityChange(before, after, typeFile); explanation != "" {
+		return explanation
+	}
+
+	deleted, added := differences(signatureParameters(before, typeFile), signatureParameters(after, typeFile))
+	positionalOnly := positionalOnlyParameters(before, typeFile)
+	if 0 == len(deleted) && 0 == len(added) {
+		if explanation := declarationChanges(before, after, typeFile); explanation != "" {
+			return explanation
+		}
+		if "go" == typeFile {
+			// Only the receiver name or the parameters grouping changed
+			return ""
+		}
+		if "java" == typeFile && compacted(withoutThrows(before)) == compacted(withoutThrows(after)) {
+			// Only exceptions callers don't have to catch changed
+			return ""
+		}
+		if madeConst(before, after, typeFile) {
+			// Now callable on const objects too, existing callers are unaffected
+			return ""
+		}
+	}
+	if len(deleted) > len(added) {
+		if hasVariadicParameter(deleted) && !hasVariadicParameter(added) {
+			return "Variadic parameter removed"
+		}
+		if hasDefaultParameter(deleted) && !hasDefaultParameter(added) {
+			return "Deletion of default parameter"
+		}
+		return "Deletion of parameter"
+	} else if len(deleted) < len(added) {
+		parametersBefore := signatureParameters(before, typeFile)
+		parametersAfter := signatureParameters(after, typeFile)
+		if explanation := compoundChange(parametersBefore, parametersAfter); explanation != "" {
+			return explanation
+		}
+		for _, e := range alignedDifferences(parametersBefore, parametersAfter) {
+			if "" == e.before && !isOptional(e.after, typeFile) {
+				return "Adding a parameter without default value"
+			}
+		}
+		for _, e := range alignedDifferences(parametersBefore, parametersAfter) {
+			if "" != e.before && "" != e.after {
+				if explanation := parameterChange(e.before, e.after, typeFile, positionalOnly); explanation != "" {
+					return explanation
+				}
+			}
+		}
+		// Only defaulted parameters added, callers aren't concerned
+		return ""
+	} else {
+		if hasNamedArguments(typeFile) && isReordering(deleted, added) && allDefaultParameters(deleted) {
+			// Callers pass them by name, order doesn't matter
+			return ""
+		}
+		if "rb" == typeFile && isReordering(deleted, added) && allRubyKeywords(deleted) {
+			// Keyword arguments are passed by name
+			return ""
+		}
+		if 0 != len(deleted) && isReordering(deleted, added) {
+			// Positional callers pass arguments in the former order
+			return "Parameters reordered"
+		}
+		if "swift" == typeFile {
+			if explanation, ok := labelChanges(deleted, added); ok {
+				return explanation
+			}
+		}
+		if 0 == len(deleted) {
+			return "Unknown signature change"
+		}
+		if explanation := compoundChange(signatureParameters(before, typeFile), signatureParameters(after, typeFile)); explanation != "" {
+			return explanation
+		}
+		for i := range deleted {
+			if explanation := parameterChange(deleted[i], added[i], typeFile, positionalOnly); explanation != "" {
+				return explanation
+			}
+		}
+		return ""
+	}
+}
+
+// positionalOnlyParameters are the python parameters of a signature callers
+// can't pass by name, those preceding `/`
+func positionalOnlyParameters(signature string, typeFile string) map[string]bool {
+	positionalOnly := make(map[string]bool)
+	if "py" != typeFile {
+		return positionalOnly
+	}
+	parameters := signatureParameters(signature, typeFile)
+	for i, parameter := range parameters {
+		if "/" == parameter {
+			for _, p := range parameters[:i] {
+				positionalOnly[p] = true
+			}
+			break
+		}
+	}
+
+	return positionalOnly
+}
+
+// parameterChange explains the change of a parameter into another one, at the
+// same position. Renaming positionalOnly ones doesn't matter to callers
+func parameterChange(before string, after string, typeFile string, positionalOnly map[string]bool) string {
+	if compacted(before) == compacted(after) {
+		// Only reformatted
+		return ""
+	}
+	if variadicBefore, variadicAfter := isVariadic(before), isVariadic(after); variadicBefore != variadicAfter {
+		if variadicAfter {
+			return "Parameter made variadic"
+		}
+		return "Variadic parameter removed"
+	}
+	if hasTypedParameters(typeFile) {
+		_, typeBefore := parameterParts(before, typeFile)
+		_, typeAfter := parameterParts(after, typeFile)
+		// Dropping a type isn't a break
+		if typeBefore != typeAfter && typeAfter != "" {
+			return "Parameter type changed"
+		}
+	}
+	if hasNamedArguments(typeFile) && !strings.HasPrefix(before, "*") {
+		nameBefore, typeBefore := parameterParts(before, typeFile)
+		nameAfter, typeAfter := parameterParts(after, typeFile)
+		if nameBefore != nameAfter && typeBefore == typeAfter {
+			if positionalOnly[before] {
+				// Callers can't pass it by name
+				return ""
+			}
+			// Callers may pass it by name
+			return "Parameter renamed"
+		}
+	}
+
+	if "rb" == typeFile {
+		if keywordBefore, keywordAfter := isRubyKeyword(before), isRubyKeyword(after); keywordBefore != keywordAfter {
+			if keywordAfter {
+				// Positional callers don't give its name
+				return "Parameter made keyword"
+			}
+			return "Keyword parameter made positional"
+		}
+	}
+
+	optionalBefore := isOptional(before, typeFile)
+	optionalAfter := isOptional(after, typeFile)
+	if defaultBefore, defaultAfter := defaultValue(before), defaultValue(after); defaultBefore != "" && defaultAfter != "" && defaultBefore != defaultAfter {
+		return "Default value changed"
+	}
+	if optionalBefore && !optionalAfter {
+		return "Deletion of default parameter"
+	}
+	if !optionalBefore && optionalAfter {
+		// Callers still give it
+		return ""
+	}
+	if hasTypedParameters(typeFile) {
+		// Same type, only name or default value changed
+		return ""
+	}
+
+	return "Unknown signature change"
+}
+
+// declarationChanges explains changes occurred out of the parameter list
+func declarationChanges(before string, after string, typeFile string) string {
+	switch typeFile {
+	case "go":
+		if goReceiverType(before) != goReceiverType(after) {
+			return "Receiver type changed"
+		}
+		if typeBefore, typeAfter := returnType(before, typeFile), returnType(after, typeFile); typeBefore != typeAfter {
+			return returnTypeChange(typeBefore, typeAfter)
+		}
+		if typeParameters(before) != typeParameters(after) {
+			return "Type parameters changed"
+		}
+	case "php":
+		// `: ?Type`, nullability included
+		if typeBefore, typeAfter := returnType(before, typeFile), returnType(after, typeFile); typeBefore != typeAfter {
+			return returnTypeChange(typeBefore, typeAfter)
+		}
+	case "cs":
+		if typeBefore, typeAfter := csReturnType(before), csReturnType(after); compacted(typeBefore) != compacted(typeAfter) {
+			return returnTypeChange(typeBefore, typeAfter)
+		}
+	case "java":
+		if typeBefore, typeAfter := javaReturnType(before), javaReturnType(after); compacted(typeBefore) != compacted(typeAfter) {
+			return returnTypeChange(typeBefore, typeAfter)
+		}
+		thrownBefore := thrownExceptions(before)
+		for exception := range thrownExceptions(after) {
+			if !thrownBefore[exception] && !isUncheckedException(exception) {
+				return "Added checked exception"
+			}
+		}
+	}
+
+	return ""
+}
+
+// returnTypeChange explains the change of a return type, narrowed or widened
+// when it's known from collection types, arrays or catch-all types. Other
+// changes can't be qualified without type analysis
+func returnTypeChange(before string, after string) string {
+	switch {
+	case isNarrower(after, before):
+		return "Return type narrowed"
+	case isNarrower(before, after):
+		return "Return type widened"
+	}
+
+	return "Return type changed"
+}
+
+// isNarrower tells if a type is known to be narrower than another: a fixed
+// size go array of a slice (`[2]T`, `[]T`), an implementation of a
+// collection interface with the same type arguments (`ArrayList<T>`,
+// `List<T>`) or anything of a catch-all type
+func isNarrower(narrow string, wide string) bool {
+	narrow, wide = compacted(narrow), compacted(wide)
+	if narrow == wide {
+		return false
+	}
+	if catchAllTypes[wide] {
+		return !catchAllTypes[narrow]
+	}
+	if element := strings.TrimPrefix(wide, "[]"); element != wide {
+		if matches := goArrayPattern.FindStringSubmatch(narrow); matches != nil {
+			return matches[1] == element
+		}
+		return false
+	}
+	baseNarrow, argumentsNarrow := genericParts(narrow)
+	baseWide, argumentsWide := genericParts(wide)
+
+	return argumentsNarrow == argumentsWide && collectionSupertypes[baseNarrow][baseWide]
+}
+
+// goArrayPattern matches a fixed size go array, with its element type
+var goArrayPattern = regexp.MustCompile(`^\[[^\]]+\](.+)$`)
+
+// catchAllTypes are types any other type is narrower than
+var catchAllTypes = map[string]bool{
+	"any": true, "interface{}": true, "Object": true, "object": true, "mixed": true,
+}
+
+// genericParts splits a type into its base type and its type arguments
+// (`List<T>` -> `List`, `<T>`)
+func genericParts(typeName string) (string, string) {
+	if position := strings.Index(typeName, "<"); position != -1 {
+		return typeName[:position], typeName[position:]
+	}
+
+	return typeName, ""
+}
+
+// collectionSupertypes are the supertypes of usual collection types (java,
+// kotlin, c#), by type
+var collectionSupertypes = map[string]map[string]bool{
+	"Collection":    {"Iterable": true},
+	"List":          {"Collection": true, "Iterable": true, "IList": true, "ICollection": true, "IEnumerable": true, "IReadOnlyList": true, "IReadOnlyCollection": true},
+	"Set":           {"Collection": true, "Iterable": true},
+	"Queue":         {"Collection": true, "Iterable": true},
+	"Deque":         {"Queue": true, "Collection": true, "Iterable": true},
+	"SortedSet":     {"Set": true, "Collection": true, "Iterable": true},
+	"ArrayList":     {"List": true, "Collection": true, "Iterable": true},
+	"LinkedList":    {"List": true, "Deque": true, "Queue": true, "Collection": true, "Iterable": true},
+	"HashSet":       {"Set": true, "Collection": true, "Iterable": true, "ISet": true, "ICollection": true, "IEnumerable": true},
+	"LinkedHashSet": {"HashSet": true, "Set": true, "Collection": true, "Iterable": true},
+	"TreeSet":       {"SortedSet": true, "NavigableSet": true, "Set": true, "Collection": true, "Iterable": true},
+	"SortedMap":     {"Map": true},
+	"HashMap":       {"Map": true},
+	"LinkedHashMap": {"HashMap": true, "Map": true},
+	"TreeMap":       {"SortedMap": true, "NavigableMap": true, "Map": true},
+	"Dictionary":    {"IDictionary": true, "IReadOnlyDictionary": true, "IEnumerable": true},
+	"IList":         {"ICollection": true, "IEnumerable": true},
+	"ICollection":   {"IEnumerable": true},
+	"MutableList":   {"List": true, "Collection": true, "Iterable": true},
+	"MutableSet":    {"Set": true, "Collection": true, "Iterable": true},
+	"MutableMap":    {"Map": true},
+}
+
+// thrownExceptions lists exceptions of the throws clause of a java signature
+func thrownExceptions(signature string) map[string]bool {
+	thrown := make(map[string]bool)
+	declaration := returnType(signature, "java")
+	position := strings.Index(declaration, "throws ")
+	if position == -1 {
+		return thrown
+	}
+	for _, exception := range strings.Split(declaration[position+len("throws "):], ",") {
+		thrown[strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(exception), ";"))] = true
+	}
+
+	return thrown
+}
+
+// throwsPattern matches the throws clause of a java signature
+var throwsPattern = regexp.MustCompile(`\s+throws [^{;]+`)
+
+// withoutThrows drops the throws clause of a java signature
+func withoutThrows(signature string) string {
+	return throwsPattern.ReplaceAllString(signature, " ")
+}
+
+// isUncheckedException tells if a java exception doesn't need to be caught by
+// callers. Only the JDK usual suspects are known
+func isUncheckedException(exception string) bool {
+	switch exception {
+	case "Error", "IllegalArgumentException", "IllegalStateException", "NullPointerException",
+		"UnsupportedOperationException", "IndexOutOfBoundsException", "ClassCastException", "ArithmeticException":
+		return true
+	}
+
+	return strings.HasSuffix(exception, "RuntimeException")
+}
+
+func hasDefaultParameter(slice []string) bool {
+	for _, s := range slice {
+		if isOptionalParameter(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasVariadicParameter tells if one of parameters is variadic
+func hasVariadicParameter(slice []string) bool {
+	for _, s := range slice {
+		if isVariadic(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func allDefaultParameters(slice []string) bool {
+	for _, s := range slice {
+		if !isOptionalParameter(s) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isOptionalParameter tells if a caller can omit a parameter, because it has
+// a default value, it's declared optional (`name?: Type`), it's variadic
+// (`args ...int`) or it's a keyword-only marker (`*args`, `**kwargs`, `*`, `/`)
+func isOptionalParameter(parameter string) bool {
+	if position := strings.Index(parameter, ":"); position > 0 && '?' == parameter[position-1] {
+		// name?: Type
+		return true
+	}
+
+	return strings.Contains(parameter, "=") || strings.HasPrefix(parameter, "*") || parameter == "/" || isVariadic(parameter)
+}
+
+// isOptional tells if a caller can omit a parameter in a langage, ruby
+// keyword parameters with a default value (`name: default`) included
+func isOptional(parameter string, typeFile string) bool {
+	if "rb" == typeFile && isRubyKeyword(parameter) {
+		return "" != strings.TrimSpace(parameter[strings.Index(parameter, ":")+1:])
+	}
+
+	return isOptionalParameter(parameter)
+}
+
+// rubyKeywordPattern matches a ruby keyword parameter (`name:`, `name: default`)
+var rubyKeywordPattern = regexp.MustCompile(`^(\s)*[a-z_][A-Za-z0-9_]*:([^:]|$)`)
+
+// isRubyKeyword tells if a ruby parameter is a keyword one
+func isRubyKeyword(parameter string) bool {
+	return rubyKeywordPattern.MatchString(parameter)
+}
+
+// allRubyKeywords tells if all ruby parameters are keyword ones
+func allRubyKeywords(parameters []string) bool {
+	for _, parameter := range parameters {
+		if !isRubyKeyword(parameter) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isVariadic tells if a parameter takes any number of arguments (`args ...int`,
+// `String... args`, `...args`)
+func isVariadic(parameter string) bool {
+	return strings.Contains(parameter, "...")
+}
+
+// isReordering checks if two slices hold the same parameters in a different order
+func isReordering(before []string, after []string) bool {
+	if len(before) != len(after) {
+		return false
+	}
+	count := make(map[string]int)
+	for _, b := range before {
+		count[b]++
+	}
+	for _, a := range after {
+		count[a]--
+		if count[a] < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasOverloading tells if the langage allows several methods of the same name
+func hasOverloading(typeFile string) bool {
+	switch typeFile {
+	case "java", "cs", "kt", "ts", "tsx", "swift", "cpp", "cc", "cxx", "h", "hh", "hpp", "hxx":
+		return true
+	}
+
+	return false
+}
+
+// hasNamedArguments tells if the langage allows callers to pass arguments by name
+func hasNamedArguments(typeFile string) bool {
+	return "py" == typeFile || "kt" == typeFile
+}
+
+// hasTypedParameters tells if parameters of the langage carry their type
+func hasTypedParameters(typeFile string) bool {
+	switch typeFile {
+	case "go", "java", "cs", "ts", "tsx", "kt", "rs", "php", "c", "cpp", "cc", "cxx", "h", "hh", "hpp", "hxx":
+		return true
+	}
+
+	return false
+}
+
+// isTypeFirst tells if parameters of the langage declare their type before
+// their name (`Type name`)
+func isTypeFirst(typeFile string) bool {
+	switch typeFile {
+	case "java", "cs", "php", "c", "cpp", "cc", "cxx", "h", "hh", "hpp", "hxx":
+		return true
+	}
+
+	return false
+}
+
+// parameterParts splits a parameter into its name and its type, dropping its
+// default value
+func parameterParts(parameter string, typeFile string) (string, string) {
+	if position := defaultValueIndex(parameter); position != -1 {
+		parameter = parameter[:position]
+	}
+	parameter = strings.TrimSpace(parameter)
+	switch typeFile {
+	case "go":
+		// name Type
+		fields := strings.SplitN(parameter, " ", 2)
+		if len(fields) < 2 {
+			return parameter, ""
+		}
+		return fields[0], strings.TrimSpace(fields[1])
+	case "java", "cs", "php":
+		// Type name, the type being optional in php
+		position := strings.LastIndex(parameter, " ")
+		if position == -1 {
+			return parameter, ""
+		}
+		return parameter[position+1:], strings.TrimSpace(parameter[:position])
+	case "c", "cpp", "cc", "cxx", "h", "hh", "hpp", "hxx":
+		return cppParameterParts(parameter)
+	}
+
+	// name: Type
+	parts := strings.SplitN(parameter, ":", 2)
+	if len(parts) < 2 {
+		return parameter, ""
+	}
+
+	return strings.TrimSuffix(strings.TrimSpace(parts[0]), "?"), strings.TrimSpace(parts[1])
+}
+
+// cppTypeKeywords are c++ keywords ending a type, thus never a parameter name
+var cppTypeKeywords = map[string]bool{
+	"auto": true, "bool": true, "char": true, "const": true, "double": true, "float": true, "int": true,
+	"long": true, "short": true, "signed": true, "unsigned": true, "void": true, "volatile": true,
+}
+
+// cppDeclaratorPattern matches the name ending a c++ declarator, with the
+// pointer and reference marks and array bounds around it
+var cppDeclaratorPattern = regexp.MustCompile(`^(.*[\s*&])([A-Za-z_][A-Za-z0-9_]*)((\s*\[[^\]]*\])*)$`)
+
+// cppParameterParts splits a c++ parameter declaration (`const T& name`,
+// `T *name`, `int`) into its name, empty if unnamed, and its type, pointer
+// and reference marks included
+func cppParameterParts(parameter string) (string, string) {
+	name, typePart := "", parameter
+	if matches := cppDeclaratorPattern.FindStringSubmatch(parameter); matches != nil && !cppTypeKeywords[matches[2]] && "" != strings.TrimSpace(matches[1]) {
+		name, typePart = matches[2], matches[1]+matches[3]
+	}
+	typePart = strings.Join(strings.Fields(typePart), " ")
+	typePart = strings.NewReplacer(" *", "*", " &", "&", " [", "[").Replace(typePart)
+
+	return name, typePart
+}
+
+// defaultValue returns the default value of a parameter, empty if none
+func defaultValue(parameter string) string {
+	position := defaultValueIndex(parameter)
+	if position == -1 {
+		return ""
+	}
+
+	return strings.TrimSpace(parameter[position+1:])
+}
+
+// defaultValueIndex is the position of the `=` introducing the default value
+// of a parameter, -1 if none. Arrows (`=>`) and comparisons aren't ones
+func defaultValueIndex(parameter string) int {
+	for i, r := range parameter {
+		if r != '=' {
+			continue
+		}
+		if i+1 < len(parameter) && (parameter[i+1] == '>' || parameter[i+1] == '=') {
+			continue
+		}
+		if i > 0 && strings.ContainsRune("=!<>", rune(parameter[i-1])) {
+			continue
+		}
+		return i
+	}
+
+	return -1
+}
+
+// labelChanges compares external labels of aligned swift parameters, as
+// they're part of the call site (`move(to: p)`), unlike internal names.
+// It fails if anything but names changed
+func labelChanges(deleted []string, added []string) (string, bool) {
+	explanation := ""
+	for i := range deleted {
+		labelBefore, typeBefore := labelledParameter(deleted[i])
+		labelAfter, typeAfter := labelledParameter(added[i])
+		if typeBefore != typeAfter {
+			return "", false
+		}
+		if labelBefore != labelAfter {
+			explanation = "Parameter label changed"
+		}
+	}
+
+	return explanation, true
+}
+
+// labelledParameter splits a swift parameter (`to point: CGPoint`) into its
+// external label and its type
+func labelledParameter(parameter string) (string, string) {
+	parts := strings.SplitN(parameter, ":", 2)
+	names := strings.Fields(parts[0])
+	if len(parts) < 2 || len(names) == 0 {
+		return parameter, ""
+	}
+
+	return names[0], strings.TrimSpace(parts[1])
+}
+
+// signatureParameters returns parameters of a signature, taking care of
+// langages allowing to omit parenthesis or having a receiver
+func signatureParameters(signature string, typeFile string) []string {
+	switch typeFile {
+	case "rb":
+		if !strings.Contains(signature, "(") {
+			// def name a, b
+			fields := strings.SplitN(strings.TrimSpace(signature), " ", 3)
+			if len(fields) < 3 {
+				return make([]string, 0)
+			}
+			signature = "(" + fields[2] + ")"
+		}
+	case "go":
+		return goGroupsSpread(parameters(goReceiverPattern.ReplaceAllString(signature, "func ")))
+	case "rs":
+		// pub(crate) fn name(
+		signature = rustVisibilityPattern.ReplaceAllString(signature, "pub")
+	}
+
+	return parameters(signature)
+}
+
+// rustVisibilityPattern matches a restricted rust visibility (`pub(crate)`)
+var rustVisibilityPattern = regexp.MustCompile(`\bpub\([^)]*\)`)
+
+// goGroupsSpread spreads the type of go grouped parameters (`a, b int`) over
+// each of their names, as `a int, b int`
+func goGroupsSpread(parameters []string) []string {
+	if !goNamedParameters(parameters) {
+		// Only types
+		return parameters
+	}
+	spread := make([]string, len(parameters))
+	groupType := ""
+	for i := len(parameters) - 1; i >= 0; i-- {
+		fields := strings.Fields(parameters[i])
+		if len(fields) > 1 {
+			groupType = strings.TrimSpace(strings.TrimSpace(parameters[i])[len(fields[0]):])
+			spread[i] = parameters[i]
+		} else if groupType != "" {
+			spread[i] = parameters[i] + " " + groupType
+		} else {
+			spread[i] = parameters[i]
+		}
+	}
+
+	return spread
+}
+
+// goNamedParameters tells if go parameters are named, otherwise they're only
+// types (`func(int, string)`)
+func goNamedParameters(parameters []string) bool {
+	for _, parameter := range parameters {
+		if len(strings.Fields(parameter)) > 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// goReceiverPattern matches the receiver of a go method
+var goReceiverPattern = regexp.MustCompile(`^(\s)*func \([^)]*\) `)
+
+// goReceiverType extracts the type of the receiver of a go method (`*Server`),
+// empty for a function
+func goReceiverType(signature string) string {
+	receiver := goReceiverPattern.FindString(signature)
+	if receiver == "" {
+		return ""
+	}
+	fields := strings.Fields(receiver[strings.Index(receiver, "(")+1 : strings.LastIndex(receiver, ")")])
+	if 0 == len(fields) {
+		return ""
+	}
+
+	return fields[len(fields)-1]
+}
+
+// returnType extracts what is declared after the parameter list of a
+// signature, up to the opening of the body
+func returnType(signature string, typeFile string) string {
+	if "go" == typeFile {
+		signature = goReceiverPattern.ReplaceAllString(signature, "func ")
+	}
+	_, rest := splitSignature(signature)
+	if body := strings.Index(rest, " {"); body != -1 {
+		rest = rest[:body]
+	}
+
+	rest = strings.TrimSuffix(strings.TrimSpace(rest), "{")
+	if "php" == typeFile {
+		rest = strings.TrimPrefix(strings.TrimSuffix(strings.TrimSpace(rest), ";"), ":")
+	}
+
+	return strings.TrimSpace(rest)
+}
+
+// typeParameters extracts type parameters of a go signature (`[T any]`)
+func typeParameters(signature string) string {
+	matches := goTypeParametersPattern.FindStringSubmatch(signature)
+	if matches == nil {
+		return ""
+	}
+
+	return matches[4]
+}
+
+// splitSignature separates the content of the parameter list of a signature
+// from what follows its closing parenthesis
+func splitSignature(signature string) (string, string) {
+	start := strings.Index(signature, "(")
+	if start == -1 {
+		return "", ""
+	}
+
+	depth := 0
+	for i, r := range signature[start+1:] {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				end := start + 1 + i
+				return signature[start+1 : end], signature[end+1:]
+			}
+			depth--
+		}
+	}
+
+	return signature[start+1:], ""
+}
+
+// parameters extracts the parameter list of a signature, splitting it on
+// commas which aren't nested in brackets or parenthesis
+func parameters(signature string) []string {
+	list, _ := splitSignature(signature)
+	params := make([]string, 0)
+	var current strings.Builder
+	depth := 0
+	for _, r := range list {
+		switch r {
+		case '(', '[', '{', '<':
+			depth++
+		case ')', ']', '}', '>':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				params = append(params, strings.TrimSpace(current.String()))
+				current.Reset()
+				continue
+			}
+		}
+		current.WriteRune(r)
+	}
+	// A trailing comma doesn't introduce a parameter
+	if last := strings.TrimSpace(current.String()); last != "" {
+		params = append(params, last)
+	}
+
+	return params
+}
+
+// compoundChange explains parameters removed at a position while others are
+// added at another one, instead of taking them as replacing each other
+func compoundChange(before []string, after []string) string {
+	var removed []string
+	var added []string
+	for _, e := range alignedDifferences(before, after) {
+		if "" == e.after {
+			removed = append(removed, "-"+e.before)
+		} else if "" == e.before {
+			added = append(added, "+"+e.after)
+		}
+	}
+	if 0 == len(removed) || 0 == len(added) {
+		return ""
+	}
+
+	return "Parameter removed and parameter added (" + strings.Join(append(removed, added...), ", ") + ")"
+}
+
+// differences shows slices of differences (deletion, adding) between two
+// slices, aligning them on their longest common subsequence
+func differences(before []string, after []string) ([]string, []string) {
+	var deleted []string
+	var added []string
+	for _, e := range alignedDifferences(before, after) {
+		if "" != e.before {
+			deleted = append(deleted, e.before)
+		}
+		if "" != e.after {
+			added = append(added, e.after)
+		}
+	}
+
+	return deleted, added
+}
+
+// edit is a difference between two slices: a deletion (after is empty), an
+// adding (before is empty) or a replacement at the same position
+type edit struct {
+	before string
+	after  string
+}
+
+// alignedDifferences lists differences between two slices, aligning them on
+// their longest common subsequence. Deletions and addings between the same
+// common elements are paired as replacements, in order
+func alignedDifferences(before []string, after []string) []edit {
+	lengthBefore := len(before)
+	lengthAfter := len(after)
+
+	// lcs[i][j] is the length of the longest common subsequence of before[i:] and after[j:]
+	lcs := make([][]int, lengthBefore+1)
+	for i := range lcs {
+		lcs[i] = make([]int, lengthAfter+1)
+	}
+	for i := lengthBefore - 1; i >= 0; i-- {
+		for j := lengthAfter - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var edits []edit
+	var deleted []string
+	var added []string
+	i, j := 0, 0
+	for i < lengthBefore || j < lengthAfter {
+		if i < lengthBefore && j < lengthAfter && before[i] == after[j] {
+			edits = append(edits, pairedEdits(deleted, added)...)
+			deleted, added = nil, nil
+			i++
+			j++
+		} else if j == lengthAfter || (i < lengthBefore && lcs[i+1][j] >= lcs[i][j+1]) {
+			deleted = append(deleted, before[i])
+			i++
+		} else {
+			added = append(added, after[j])
+			j++
+		}
+	}
+
+	return append(edits, pairedEdits(deleted, added)...)
+}
+
+// pairedEdits pairs deletions and addings occurred at the same position
+func pairedEdits(deleted []string, added []string) []edit {
+	edits := make([]edit, 0, len(deleted)+len(added))
+	for k := 0; k < len(deleted) || k < len(added); k++ {
+		e := edit{}
+		if k < len(deleted) {
+			e.before = deleted[k]
+		}
+		if k < len(added) {
+			e.after = added[k]
+		}
+		edits = append(edits, e)
+	}
+
+	return edits
+}
+
+// extractDataFile gives file's status, name, previous name (if renamed) and
+// type. Lines which aren't a changed file have no status
+func extractDataFile(fileLine string) (string, string, string, string) {
+	fields := strings.Split(fileLine, "\t")
+	if len(fields) < 2 {
+		// Not a changed file, as git warnings (rename limit…), skipped
+		return "", "", "", ""
+	}
+	status := fields[0]
+	name := fields[1]
+	var previousName string
+	if strings.HasPrefix(status, "R") && len(fields) > 2 {
+		// R100	old	new
+		status = "R"
+		previousName = name
+		name = fields[2]
+	}
+
+	return status, name, previousName, typefile(name)
+}
+
+// typeFile return a file's extension, lower-cased. With compound extensions,
+// the last one wins (`schema.d.ts` is `ts`, `Foo.test.js` is `js`). Leading
+// dots of dotfiles aren't extensions (`.eslintrc.js` is `js`, `.bashrc` has none)
+func typefile(filepath string) string {
+	filename := strings.TrimLeft(path.Base(filepath), ".")
+	position := strings.LastIndex(filename, ".")
+	if position == -1 {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSpace(filename[position+1:]))
+}
+
+// diff represents the diff of a file, segregated with deletion and adding
+type diff struct {
+	deletions []string
+	addings   []string
+	// hidden are additions of declarations out of the public API
+	hidden []string
+	// abstractDeletions and abstractAddings are members of abstract
+	// declarations already existing (interfaces…), to be implemented
+	abstractDeletions []string
+	abstractAddings   []string
+	// unchanged are declarations untouched by the diff, only looked for in
+	// langages allowing overloads
+	unchanged []string
+	// kinds are kinds of block members, blocks telling them apart
+	kinds map[string]string
+	// lines are line numbers of declarations, by side and text
+	lines map[string]int
+	// deprecated are deletions carrying a deprecation marker in the old version
+	deprecated map[string]bool
+}
+
+// line returns the line number of a declaration on a side ("-" for the old
+// version, "+" for the new one), 0 if unknown
+func (d *diff) line(side string, declaration string) int {
+	return d.lines[side+declaration]
+}
+
+// record adds declarations of a side, with their line numbers. The first
+// occurrence of a declaration gives its line
+func (d *diff) record(side string, declarations []string, lines []int) {
+	if d.lines == nil {
+		d.lines = make(map[string]int)
+	}
+	for i, declaration := range declarations {
+		if _, known := d.lines[side+declaration]; !known {
+			d.lines[side+declaration] = lines[i]
+		}
+	}
+	if "-" == side {
+		d.deletions = append(d.deletions, declarations...)
+	} else {
+		d.addings = append(d.addings, declarations...)
+	}
+}
+
+// getDiff fetches diff (in a git sense) and extracts changes occured
+func (f *file) getDiff(ctx context.Context, b Break) (*diff, error) {
+	if f.isDeleted() {
+		return f.getDiffDeleted(ctx, b)
+	}
+	diffFile, err := b.diffFile(ctx, f.paths()...)
+	if err != nil {
+		return nil, err
+	}
+	if isBinaryDiff(diffFile) {
+		f.binary = true
+		return &diff{}, nil
+	}
+
+	return f.changes(diffFile)
+}
+
+// isBinaryDiff tells if git reports a diff as binary (`Binary files a/x and b/x differ`)
+func isBinaryDiff(diffLines []string) bool {
+	for _, line := range diffLines {
+		if strings.HasPrefix(line, "Binary files ") && strings.HasSuffix(line, " differ") {
+			return true
+		}
+		if strings.HasPrefix(line, "@@") {
+			// Text hunks started
+			return false
+		}
+	}
+
+	return false
+}
+
+// changes extracts signatures deleted and added in the lines of a diff
+func (f *file) changes(diffLines []string) (*diff, error) {
+	pattern, errPattern := f.breakPattern()
+	if errPattern != nil {
+		return nil, errPattern
+	}
+
+	// Deprecation markers are often comments
+	commented := diffLines
+	diffLines = f.withoutBodies(f.withoutComments(diffLines))
+	d := &diff{}
+	for _, side := range []string{"-", "+"} {
+		declarations, lines := f.exported(signatures(pattern, diffLines, side))
+		d.record(side, declarations, lines)
+		if typePattern := f.typePattern(); typePattern != nil {
+			declarations, lines = signatures(typePattern, diffLines, side)
+			declarations, lines = f.exported(withoutMatches(pattern, declarations, lines))
+			d.record(side, declarations, lines)
+		}
+		if constantPattern := f.constantPattern(); constantPattern != nil {
+			declarations, lines = constants(constantPattern, diffLines, side)
+			d.record(side, declarations, lines)
+		}
+		declarations, lines, kinds := f.blocksMembers(diffLines, side)
+		d.record(side, declarations, lines)
+		for i, declaration := range declarations {
+			if d.kinds == nil {
+				d.kinds = make(map[string]string)
+			}
+			d.kinds[declaration] = kinds[i]
+		}
+	}
+	d.deprecated = deprecatedDeletions(commented, d.deletions, d.lines)
+	if hasOverloading(f.typeFile) {
+		d.unchanged, _ = signatures(pattern, diffLines, " ")
+	}
+	d.abstractDeletions, _ = f.abstractMembers(diffLines, "-")
+	var abstractLines []int
+	d.abstractAddings, abstractLines = f.abstractMembers(diffLines, "+")
+	for i, declaration := range d.abstractAddings {
+		if _, known := d.lines["+"+declaration]; !known {
+			d.lines["+"+declaration] = abstractLines[i]
+		}
+	}
+	if hiddenPattern := f.hiddenPattern(); hiddenPattern != nil {
+		hidden, lines := signatures(hiddenPattern, diffLines, "+")
+		for i, declaration := range hidden {
+			if pattern.MatchString(declaration) {
+				// Public after all
+				continue
+			}
+			if _, known := d.lines["+"+declaration]; !known {
+				d.lines["+"+declaration] = lines[i]
+			}
+			d.hidden = append(d.hidden, declaration)
+		}
+	}
+
+	return d, nil
+}
+
+// exported drops declarations with an internal linkage (c `static`
+// functions), with their line numbers
+func (f *file) exported(declarations []string, lines []int) ([]string, []int) {
+	if "c" != f.typeFile {
+		return declarations, lines
+	}
+	keptDeclarations := make([]string, 0, len(declarations))
+	keptLines := make([]int, 0, len(lines))
+	for i, declaration := range declarations {
+		if !strings.HasPrefix(declaration, "static ") {
+			keptDeclarations = append(keptDeclarations, declaration)
+			keptLines = append(keptLines, lines[i])
+		}
+	}
+
+	return keptDeclarations, keptLines
+}
+
+// withoutMatches drops declarations matching pattern, already recorded as
+// methods (`struct foo *make(int a) {`), with their line numbers
+func withoutMatches(pattern *regexp.Regexp, declarations []string, lines []int) ([]string, []int) {
+	keptDeclarations := make([]string, 0, len(declarations))
+	keptLines := make([]int, 0, len(lines))
+	for i, declaration := range declarations {
+		if !pattern.MatchString(declaration) {
+			keptDeclarations = append(keptDeclarations, declaration)
+			keptLines = append(keptLines, lines[i])
+		}
+	}
+
+	return keptDeclarations, keptLines
+}
+
+// deprecationPattern matches a deprecation marker (`@Deprecated`,
+// `// Deprecated:`, `#[deprecated]`, `[Obsolete]`…)
+var deprecationPattern = regexp.MustCompile(`(?i)\bdeprecated\b|\[Obsolete\b`)
+
+// deprecatedDeletions tells which deletions carried a deprecation marker in
+// the old version, on their line or in the comments and annotations right
+// above them
+func deprecatedDeletions(diffLines []string, deletions []string, lines map[string]int) map[string]bool {
+	old := make(map[int]string)
+	for i, number := range lineNumbers(diffLines, "-") {
+		if number > 0 {
+			old[number] = strings.TrimSpace(diffLines[i][1:])
+		}
+	}
+	deprecated := make(map[string]bool)
+	for _, deletion := range deletions {
+		for number := lines["-"+deletion]; number > 0; number-- {
+			line, known := old[number]
+			if number != lines["-"+deletion] && (!known || !isCommentOrAnnotation(line)) {
+				break
+			}
+			if deprecationPattern.MatchString(line) {
+				deprecated[deletion] = true
+				break
+			}
+		}
+	}
+
+	return deprecated
+}
+
+// isCommentOrAnnotation tells if a line may be part of the comments or
+// annotations preceding a declaration
+func isCommentOrAnnotation(line string) bool {
+	for _, prefix := range []string{"//", "/*", "*"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	for _, prefix := range []string{"#", "@", "["} {
+		// An annotated declaration on a single line isn't
+		if strings.HasPrefix(line, prefix) {
+			return !strings.HasSuffix(line, "{") && !strings.HasSuffix(line, "}") && !strings.HasSuffix(line, ";")
+		}
+	}
+
+	return false
+}
+
+// withoutComments strips comment lines from a diff, so that their text can't
+// be taken for a declaration
+func (f *file) withoutComments(diffLines []string) []string {
+	lineComments := []string{"//"}
+	blockComments := true
+	switch f.typeFile {
+	case "py", "rb", "sh":
+		lineComments, blockComments = []string{"#"}, false
+	case "php":
+		lineComments = append(lineComments, "#")
+	}
+	kept := make([]string, 0, len(diffLines))
+	inBlockComment := false
+	// A hunk header keeps line numbers right after stripped lines
+	stripped := false
+	counter := newLineCounter(diffLines)
+	for _, line := range diffLines {
+		oldLine, newLine := counter.old, counter.new
+		counter.next(line)
+		if line == "" || counter.inHeader || hunkHeaderPattern.MatchString(line) {
+			kept = append(kept, line)
+			continue
+		}
+		content := strings.TrimSpace(line[1:])
+		if inBlockComment || (blockComments && strings.HasPrefix(content, "/*")) {
+			inBlockComment = !strings.Contains(strings.TrimPrefix(content, "/*"), "*/")
+			stripped = true
+			continue
+		}
+		if isLineComment(content, lineComments) {
+			stripped = true
+			continue
+		}
+		if stripped {
+			kept = append(kept, fmt.Sprintf("@@ -%d +%d @@", oldLine, newLine))
+			stripped = false
+		}
+		kept = append(kept, line)
+	}
+
+	return kept
+}
+
+// withoutBodies strips lines of c and c++ function bodies from a diff, so that
+// local constructions (`std::string s(buf);`) can't be taken for a
+// declaration
+func (f *file) withoutBodies(diffLines []string) []string {
+	switch f.typeFile {
+	case "c", "cpp", "cc", "cxx", "h", "hh", "hpp", "hxx":
+	default:
+		return diffLines
+	}
+	kept := make([]string, 0, len(diffLines))
+	// Each side has its own bodies
+	var old, new cppScope
+	// A hunk header keeps line numbers right after stripped lines
+	stripped := false
+	counter := newLineCounter(diffLines)
+	for _, line := range diffLines {
+		oldLine, newLine := counter.old, counter.new
+		counter.next(line)
+		if line == "" || counter.inHeader || hunkHeaderPattern.MatchString(line) {
+			kept = append(kept, line)
+			continue
+		}
+		change, content := line[:1], line[1:]
+		inBody := ("+" != change && old.inBody()) || ("-" != change && new.inBody())
+		if "+" != change {
+			old.next(content)
+		}
+		if "-" != change {
+			new.next(content)
+		}
+		if inBody {
+			stripped = true
+			continue
+		}
+		if stripped {
+			kept = append(kept, fmt.Sprintf("@@ -%d +%d @@", oldLine, newLine))
+			stripped = false
+		}
+		kept = append(kept, line)
+	}
+
+	return kept
+}
+
+// cppScope follows the blocks opened in a version of a c or c++ file, to tell
+// function bodies from namespaces and classes
+type cppScope struct {
+	// blocks are opened blocks, true for function bodies
+	blocks []bool
+	// signature tells if a function signature waits for its body
+	signature bool
+}
+
+// inBody tells if the current line is in a function body
+func (s *cppScope) inBody() bool {
+	return 0 != len(s.blocks) && s.blocks[len(s.blocks)-1]
+}
+
+// next follows blocks opened and closed by a line
+func (s *cppScope) next(content string) {
+	if !s.inBody() && cppBreakPattern.MatchString(content) {
+		s.signature = true
+	}
+	for _, r := range content {
+		switch r {
+		case '{':
+			s.blocks = append(s.blocks, s.signature || s.inBody())
+			s.signature = false
+		case '}':
+			if 0 != len(s.blocks) {
+				s.blocks = s.blocks[:len(s.blocks)-1]
+			}
+		case ';':
+			// A declaration, without body
+			s.signature = false
+		}
+	}
+}
+
+// isLineComment tells if a line starts with one of the comment markers
+func isLineComment(content string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.HasPrefix(content, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// paths are the paths of the file on both sides of the diff
+func (f *file) paths() []string {
+	if f.isRenamed() {
+		return []string{f.previousName, f.name}
+	}
+
+	return []string{f.name}
+}
+
+func (f *file) isRenamed() bool {
+	return "R" == f.status
+}
+
+func (f *file) isDeleted() bool {
+	return "D" == f.status
+}
+
+func (f *file) getDiffDeleted(ctx context.Context, b Break) (*diff, error) {
+	base, err := b.basePoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	diffFile, err := b.showFile(ctx, base, f.name)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range diffFile {
+		if strings.ContainsRune(line, 0) {
+			// Like git, a NUL byte is taken for a binary content
+			f.binary = true
+			return &diff{}, nil
+		}
+	}
+	for i, line := range diffFile {
+		diffFile[i] = "-" + line
+	}
+
+	return f.changes(diffFile)
+}
+
+// isClosedSignature tells if the parameters of a signature are closed. An
+// arrow function is closed at its arrow, its body may open parenthesis (JSX)
+func isClosedSignature(signature string) bool {
+	if strings.Count(signature, "(") <= strings.Count(signature, ")") {
+		return true
+	}
+	if position := strings.Index(signature, "=>"); position != -1 {
+		declaration := signature[:position]
+		return strings.Contains(declaration, "(") && strings.Count(declaration, "(") <= strings.Count(declaration, ")")
+	}
+
+	return false
+}
+
+// isThrowing tells if the throws clause of a closed signature goes on with
+// the next line
+func isThrowing(signature string, next string) bool {
+	tail := strings.TrimSpace(signature[strings.LastIndex(signature, ")")+1:])
+	if strings.ContainsAny(tail, "{;") {
+		return false
+	}
+
+	return strings.HasPrefix(next, "throws ") || (strings.HasPrefix(tail, "throws ") && strings.HasSuffix(tail, ","))
+}
+
+// nextContent returns the content of the line following the i-th one on a
+// side of a diff, empty if there is none
+func nextContent(diffLines []string, i int, side string) string {
+	for _, line := range diffLines[i+1:] {
+		if line == "" {
+			continue
+		}
+		if change := line[:1]; change == " " || change == side {
+			return strings.TrimSpace(line[1:])
+		}
+	}
+
+	return ""
+}
+
+// maxSignatureLines bounds the lines joined for a signature, in case its
+// parenthesis are never closed
+const maxSignatureLines = 30
+
+// signatures extracts signatures matching pattern on one side ("-" or "+")
+// of a diff, if the diff touches them. Signatures spread over several lines
+// are joined up to their closing parenthesis, or their throws clause. Line numbers of signatures are
+// returned alongside
+func signatures(pattern *regexp.Regexp, diffLines []string, side string) ([]string, []int) {
+	found := make([]string, 0)
+	foundLines := make([]int, 0)
+	numbers := lineNumbers(diffLines, side)
+	var signature string
+	var lines int
+	var start int
+	touched := false
+	for i, line := range diffLines {
+		if line == "" {
+			continue
+		}
+		change, content := line[:1], strings.TrimSpace(line[1:])
+		if change != " " && change != side {
+			// The other side changed the signature being joined
+			touched = touched || (lines > 0 && (change == "-" || change == "+"))
+			continue
+		}
+		if lines > 0 {
+			signature = joinSignature(signature, content)
+		} else if pattern.MatchString(content) && !statementPattern.MatchString(content) {
+			signature = content
+			start = numbers[i]
+		} else {
+			continue
+		}
+		lines++
+		touched = touched || change == side
+		if (isClosedSignature(signature) && !isThrowing(signature, nextContent(diffLines, i, side))) || lines >= maxSignatureLines {
+			if touched {
+				found = append(found, signature)
+				foundLines = append(foundLines, start)
+			}
+			signature, lines, touched = "", 0, false
+		}
+	}
+
+	return found, foundLines
+}
+
+// hunkHeaderPattern matches a hunk header, capturing the starting lines of
+// both versions (`@@ -12,7 +12,9 @@`)
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -([0-9]+)(,[0-9]+)? \+([0-9]+)(,[0-9]+)? @@`)
+
+// lineCounter follows line numbers of both versions along a diff
+type lineCounter struct {
+	// old and new are the numbers of the next line of each version
+	old int
+	new int
+	// inHeader tells if the diff is still in its file header (`--- a/file`)
+	inHeader bool
+}
+
+// newLineCounter starts counting a diff, which may have a file header or
+// consist of bare lines (deleted file)
+func newLineCounter(diffLines []string) *lineCounter {
+	return &lineCounter{
+		old:      1,
+		new:      1,
+		inHeader: 0 != len(diffLines) && strings.HasPrefix(diffLines[0], "diff "),
+	}
+}
+
+// next counts a diff line
+func (c *lineCounter) next(line string) {
+	if matches := hunkHeaderPattern.FindStringSubmatch(line); matches != nil {
+		c.old, _ = strconv.Atoi(matches[1])
+		c.new, _ = strconv.Atoi(matches[3])
+		c.inHeader = false
+		return
+	}
+	if c.inHeader || line == "" {
+		return
+	}
+	switch line[:1] {
+	case " ":
+		c.old++
+		c.new++
+	case "-":
+		c.old++
+	case "+":
+		c.new++
+	}
+}
+
+// lineNumbers gives the number of each line of a diff in the version of a side
+// ("-" for the old one, "+" for the new one), 0 if it isn't part of it
+func lineNumbers(diffLines []string, side string) []int {
+	numbers := make([]int, len(diffLines))
+	counter := newLineCounter(diffLines)
+	for i, line := range diffLines {
+		oldLine, newLine := counter.old, counter.new
+		counter.next(line)
+		if counter.inHeader || line == "" || hunkHeaderPattern.MatchString(line) {
+			continue
+		}
+		change := line[:1]
+		if " " == change || "-" == change && "-" == side {
+			numbers[i] = oldLine
+		}
+		if " " == change && "+" == side || "+" == change && "+" == side {
+			numbers[i] = newLine
+		}
+	}
+
+	return numbers
+}
+
+// joinSignature appends a continuation line to a signature. A trailing comma
+// before the closing parenthesis is dropped
+func joinSignature(signature string, continuation string) string {
+	if strings.HasPrefix(continuation, ")") {
+		return strings.TrimSuffix(signature, ",") + continuation
+	}
+	if strings.HasSuffix(signature, "(") {
+		return signature + continuation
+	}
+
+	return signature + " " + continuation
+}
+
+// block is a declaration block (interface, struct…) whose members are part
+// of the public API
+type block struct {
+	opening *regexp.Regexp
+	member  *regexp.Regexp
+	kind    string
+	// closing closes the block, `}` if empty
+	closing string
+}
+
+// goInterfaceBlock is an exported go interface, its methods being members
+var goInterfaceBlock = block{
+	opening: regexp.MustCompile(`^(type )?(\s)*[A-Z][A-Za-z0-9_]* interface \{`),
+	member:  regexp.MustCompile(`^(\s)*[A-Z][A-Za-z0-9_]*\(`),
+	kind:    methodKind,
+}
+
+// blocksByLangage are the declaration blocks to look into, by type of file
+var blocksByLangage = map[string][]block{
+	"go": {
+		goInterfaceBlock,
+		{
+			opening: regexp.MustCompile(`^(type )?(\s)*[A-Z][A-Za-z0-9_]* struct \{`),
+			member:  regexp.MustCompile(`^(\s)*[A-Z][A-Za-z0-9_]*(\s|,)`),
+			kind:    fieldKind,
+		},
+		{
+			// Values may be implicit (`iota`)
+			opening: regexp.MustCompile(`^const \($`),
+			member:  regexp.MustCompile(`(?P<factor>^(\s)*[A-Z][A-Za-z0-9_]*)(( [^=]+)? =.*|$)`),
+			kind:    constantKind,
+			closing: ")",
+		},
+	},
+	"java": {
+		{
+			// Constants may have arguments or a body
+			opening: regexp.MustCompile(`^(\s)*public( static)?( final)? enum [A-Za-z_][A-Za-z0-9_]*`),
+			member:  regexp.MustCompile(`(?P<factor>^(\s)*[A-Z][A-Z0-9_]*)(\(.*\))?(\s*\{)?\s*[,;]?$`),
+			kind:    enumValueKind,
+		},
+	},
+	"ts":  {tsEnumBlock},
+	"tsx": {tsEnumBlock},
+	"php": {
+		{
+			// Enums are public
+			opening: regexp.MustCompile(`^(\s)*enum [A-Za-z_][A-Za-z0-9_]*`),
+			member:  regexp.MustCompile(`(?P<factor>^(\s)*case [A-Za-z_][A-Za-z0-9_]*)(\s*=.*)?;$`),
+			kind:    enumValueKind,
+		},
+	},
+}
+
+// tsEnumBlock is an exported typescript enum, its values being members
+var tsEnumBlock = block{
+	opening: regexp.MustCompile(`^(\s)*export( declare)?( const)? enum [A-Za-z_$][A-Za-z0-9_$]*`),
+	member:  regexp.MustCompile(`(?P<factor>^(\s)*([A-Za-z_$][A-Za-z0-9_$]*|"[^"]*"|'[^']*'))(\s*=.*)?,?$`),
+	kind:    enumValueKind,
+}
+
+// blocks returns the declaration blocks to look into, associated with type of the file
+func (f *file) blocks() []block {
+	return blocksByLangage[f.typeFile]
+}
+
+// blocksMembers extracts members changed on one side ("-" or "+") in
+// declaration blocks of a diff, with their line numbers. Blocks must be
+// entirely in the diff, context included
+func (f *file) blocksMembers(diffLines []string, side string) ([]string, []int, []string) {
+	return membersChanged(f.blocks(), diffLines, side, false)
+}
+
+// abstractMembers extracts members changed on one side ("-" or "+") in
+// abstract declarations existing on both sides of a diff, with their line
+// numbers
+func (f *file) abstractMembers(diffLines []string, side string) ([]string, []int) {
+	members, lines, _ := membersChanged(f.abstractBlocks(), diffLines, side, true)
+
+	return members, lines
+}
+
+// membersChanged extracts members of blocks changed on one side ("-" or "+")
+// of a diff, with their line numbers and kinds, optionally only in blocks
+// whose opening is unchanged
+func membersChanged(blocks []block, diffLines []string, side string, unchangedOnly bool) ([]string, []int, []string) {
+	members := make([]string, 0)
+	lines := make([]int, 0)
+	kinds := make([]string, 0)
+	numbers := lineNumbers(diffLines, side)
+	for _, b := range blocks {
+		var closing string
+		inBlock := false
+		for i, line := range diffLines {
+			if line == "" {
+				continue
+			}
+			change, content := line[:1], line[1:]
+			if !inBlock {
+				if b.opening.MatchString(content) && (!unchangedOnly || " " == change) {
+					inBlock = true
+					closing = content[:len(content)-len(strings.TrimLeft(content, " \t"))] + b.closer()
+				}
+				continue
+			}
+			if strings.TrimRight(content, " \t") == closing {
+				inBlock = false
+			} else if b.member.MatchString(content) && side == change {
+				members = append(members, strings.TrimSpace(content))
+				lines = append(lines, numbers[i])
+				kinds = append(kinds, b.kind)
+			}
+		}
+	}
+
+	return members, lines, kinds
+}
+
+// closer is what closes the block
+func (b block) closer() string {
+	if "" == b.closing {
+		return "}"
+	}
+
+	return b.closing
+}
+
+// abstractBlocksByLangage are the declaration blocks whose members have to
+// be implemented, by type of file
+var abstractBlocksByLangage = map[string][]block{
+	"go": {goInterfaceBlock},
+	"java": {
+		{
+			// Default and static methods have a body
+			opening: regexp.MustCompile(`^(\s)*public( sealed| non-sealed)? interface [A-Za-z_][A-Za-z0-9_]*`),
+			member:  regexp.MustCompile(`(?P<factor>^(\s)*(public )?(abstract )?[A-Za-z_][A-Za-z0-9_<>,.?\[\] ]* [A-Za-z_][A-Za-z0-9_]*\()[^{]*;$`),
+			kind:    methodKind,
+		},
+		{
+			opening: regexp.MustCompile(`^(\s)*public abstract class [A-Za-z_][A-Za-z0-9_]*`),
+			member:  regexp.MustCompile(`^(\s)*(public|protected) abstract [A-Za-z_][A-Za-z0-9_<>,.?\[\] ]* [A-Za-z_][A-Za-z0-9_]*\(`),
+			kind:    methodKind,
+		},
+	},
+	"php": {
+		{
+			opening: regexp.MustCompile(`^(\s)*interface [A-Za-z_][A-Za-z0-9_]*`),
+			member:  regexp.MustCompile(`^(\s)*public( static)? function [_A-Za-z0-9]+\(`),
+			kind:    methodKind,
+		},
+		{
+			opening: regexp.MustCompile(`^(\s)*abstract class [A-Za-z_][A-Za-z0-9_]*`),
+			member:  regexp.MustCompile(`^(\s)*(abstract (public|protected)|(public|protected) abstract)( static)? function [_A-Za-z0-9]+\(`),
+			kind:    methodKind,
+		},
+	},
+}
+
+// abstractBlocks returns the declaration blocks whose members have to be
+// implemented, associated with type of the file
+func (f *file) abstractBlocks() []block {
+	return abstractBlocksByLangage[f.typeFile]
+}
+
+// abstractFactor returns the part of a member of an abstract declaration
+// identifying it
+func (f *file) abstractFactor(line string) string {
+	for _, b := range f.abstractBlocks() {
+		if factor := matchedFactor(b.member, line); factor != "" {
+			return factor
+		}
+	}
+
+	return ""
+}
+
+// memberFactor returns the part of a block member identifying it and its
+// kind, if any. The kind of a member found in a block wins
+func (f *file) memberFactor(line string) (string, string) {
+	kind, known := f.diff.kinds[line]
+	for _, b := range f.blocks() {
+		if known && kind != b.kind {
+			continue
+		}
+		if factor := matchedFactor(b.member, line); factor != "" {
+			return factor, b.kind
+		}
+	}
+
+	return "", ""
+}
+
+// constantPatterns are the regexes of a public constant declared on its own,
+// by type of file. Constants of other langages aren't analysed
+var constantPatterns = map[string]*regexp.Regexp{
+	"go": regexp.MustCompile(`(?P<factor>^(\s)*const [A-Z][A-Za-z0-9_]*)( [^=]+)? =`),
+	// Class constants are public by default
+	"php": regexp.MustCompile(`(?P<factor>^(\s)*((final|public) )*const( [A-Za-z_?\\|]+)? [A-Za-z_][A-Za-z0-9_]*)(\s)*=`),
+}
+
+// constantPattern returns the regex of a public constant declared on its own,
+// associated with type of the file, nil if constants aren't analysed
+func (f *file) constantPattern() *regexp.Regexp {
+	return constantPatterns[f.typeFile]
+}
+
+// constants extracts constants matching pattern on one side ("-" or "+") of a
+// diff, with their line numbers
+func constants(pattern *regexp.Regexp, diffLines []string, side string) ([]string, []int) {
+	found := make([]string, 0)
+	foundLines := make([]int, 0)
+	numbers := lineNumbers(diffLines, side)
+	for i, line := range diffLines {
+		if line == "" || line[:1] != side {
+			continue
+		}
+		if content := strings.TrimSpace(line[1:]); pattern.MatchString(content) {
+			found = append(found, content)
+			foundLines = append(foundLines, numbers[i])
+		}
+	}
+
+	return found, foundLines
+}
+
+// statementPattern matches lines which can't be a declaration, whatever the
+// langage, but could look like one (`return foo(`)
+var statementPattern = regexp.MustCompile(`^(\s)*(return|else|throw|new|delete|case|goto|if|for|while|switch|catch)\b`)
+
+// isTest tells if a file holds tests, out of the public API
+func (f *file) isTest() bool {
+	return "go" == f.typeFile && strings.HasSuffix(f.name, "_test.go")
+}
+
+func (f *file) isTypeSupported() bool {
+	_, err := f.breakPattern()
+
+	return err == nil
+}
+
+// typePatterns are the regexes of a public type declaration, by type of
+// file. Types of other langages aren't analysed
+var typePatterns = map[string]*regexp.Regexp{
+	"go":    regexp.MustCompile(`^(\s)*type [A-Z][A-Za-z0-9_]*[ \[]`),
+	"java":  regexp.MustCompile(`^(\s)*public( (abstract|final|static|sealed|non-sealed|strictfp))* (class|interface|enum|record|@interface) [A-Za-z_][A-Za-z0-9_]*`),
+	"cs":    regexp.MustCompile(`^(\s)*public( (abstract|sealed|static|partial|readonly|ref|unsafe))* (class|interface|struct|enum|record) [A-Za-z_][A-Za-z0-9_]*`),
+	"kt":    regexp.MustCompile(`^(\s)*((public|open|abstract|final|data|sealed|enum|inner|value|annotation|fun) )*(class|interface|object) [A-Za-z_][A-Za-z0-9_]*`),
+	"php":   regexp.MustCompile(`^(\s)*((abstract|final|readonly) )*(class|interface|trait|enum) [A-Za-z_][A-Za-z0-9_]*`),
+	"js":    jsTypePattern,
+	"mjs":   jsTypePattern,
+	"cjs":   jsTypePattern,
+	"jsx":   jsTypePattern,
+	"ts":    tsTypePattern,
+	"tsx":   tsTypePattern,
+	"py":    regexp.MustCompile(`^(\s)*class [A-Za-z][A-Za-z0-9_]*`),
+	"rb":    regexp.MustCompile(`^(\s)*(class|module) [A-Z][A-Za-z0-9_:]*`),
+	"rs":    regexp.MustCompile(`^(\s)*pub (struct|enum|trait|type|union|mod) [A-Za-z_][A-Za-z0-9_]*`),
+	"swift": regexp.MustCompile(`^(\s)*(public|open)( (final|indirect))? (class|struct|enum|protocol|actor) [A-Za-z_][A-Za-z0-9_]*`),
+	"c":     cppTypePattern,
+	"cpp":   cppTypePattern,
+	"cc":    cppTypePattern,
+	"cxx":   cppTypePattern,
+	"h":     cppTypePattern,
+	"hh":    cppTypePattern,
+	"hpp":   cppTypePattern,
+	"hxx":   cppTypePattern,
+}
+
+var (
+	jsTypePattern = regexp.MustCompile(`^(\s)*(export( default)? )?class [A-Za-z_$][A-Za-z0-9_$]*`)
+	tsTypePattern = regexp.MustCompile(`^(\s)*export( default)?( declare)?( abstract)? (class|interface|enum|type) [A-Za-z_$][A-Za-z0-9_$]*`)
+	// Forward declarations (`class Foo;`) aren't definitions
+	cppTypePattern = regexp.MustCompile(`(?P<factor>^(\s)*(class|struct) [A-Za-z_][A-Za-z0-9_]*)[^;]*$`)
+)
+
+// typePattern returns the regex of a public type declaration associated with
+// type of the file, nil if types aren't analysed
+func (f *file) typePattern() *regexp.Regexp {
+	return typePatterns[f.typeFile]
+}
+
+// hiddenPatterns are the regexes of a declaration out of the public API, by
+// type of file. Visibility of other langages can't be reduced
+var hiddenPatterns = map[string]*regexp.Regexp{
+	"php": regexp.MustCompile(`^(\s)*((abstract|final) )?(protected|private)( static)? function [_A-Za-z]+\(`),
+	// Package-private methods have no modifier, unlike calls they end with a
+	// body. They start with a type, thus never with a keyword (`return new Foo(bar) {`)
+	"java": regexp.MustCompile(`(?P<factor>^(\s)*(private )?((static|final|abstract|synchronized|native) )*(<[^()]*> )?(void|boolean|byte|char|short|int|long|float|double|([a-z_][A-Za-z0-9_]*\.)*[A-Z][A-Za-z0-9_]*)(<[^()]*>)?(\[\])* [A-Za-z_][A-Za-z0-9_]*\()[^;]*\)( throws [^;{]+)?(\s)*\{?$`),
+	// Methods without modifier are private, they start with a type as java
+	// package-private ones
+	"cs": regexp.MustCompile(`(?P<factor>^(\s)*((private|internal) )?((static|virtual|override|abstract|sealed|async|new|extern|unsafe|partial|readonly) )*(void|bool|byte|char|short|int|long|float|double|decimal|string|object|([A-Za-z_][A-Za-z0-9_]*\.)*[A-Z][A-Za-z0-9_]*)(<[^()]*>)?(\[\])*\?? [A-Za-z_][A-Za-z0-9_]*(<[^()]+>)?\()[^;]*\)(\s)*\{?$`),
+	"kt": regexp.MustCompile(`^(\s)*((open|override|abstract|final|suspend|inline|operator|infix|tailrec|external|actual|expect) )*(private|internal) ((open|override|abstract|final|suspend|inline|operator|infix|tailrec|external|actual|expect) )*fun (<[^(]*> )?([A-Za-z_][A-Za-z0-9_<>?,. ]*\.)?[A-Za-z_][A-Za-z0-9_]*\(`),
+}
+
+// hiddenPattern returns the regex of a declaration out of the public API,
+// associated with type of the file, nil if visibility can't be reduced
+func (f *file) hiddenPattern() *regexp.Regexp {
+	return hiddenPatterns[f.typeFile]
+}
+
+// breakPatterns are the regexes of a potential compatibility break, by type
+// of file
+var breakPatterns = map[string]*regexp.Regexp{
+	"go": regexp.MustCompile(`^(\s)*func( \(.+\))? [A-Z][A-Za-z0-9_]*(\[[^(]+\])?\(`),
+	"kt": regexp.MustCompile(`^(\s)*((public|protected|open|override|abstract|final|suspend|inline|operator|infix|tailrec|external|actual|expect) )*fun (<[^(]*> )?([A-Za-z_][A-Za-z0-9_<>?,. ]*\.)?[A-Za-z_][A-Za-z0-9_]*\(`),
+	// Abstract protected methods are to be implemented by subclasses
+	"php": regexp.MustCompile(`^(\s)*((abstract|final|static) )*public( (abstract|final|static))* function [_A-Za-z][_A-Za-z0-9]*\(|^(\s)*(abstract protected|protected abstract)( static)? function [_A-Za-z][_A-Za-z0-9]*\(|^(\s)*function [_A-Za-z]+\(`),
+	// Protected methods are part of the API of subclasses
+	"java":  regexp.MustCompile(`^(\s)*(public|protected)( static)?( .+)? [A-Za-z]+\(`),
+	"js":    jsBreakPattern,
+	"mjs":   jsBreakPattern,
+	"cjs":   jsBreakPattern,
+	"jsx":   jsBreakPattern,
+	"sh":    regexp.MustCompile(`^(\s)*function [A-Za-z_]+\(`),
+	"c":     cppBreakPattern,
+	"cpp":   cppBreakPattern,
+	"cc":    cppBreakPattern,
+	"cxx":   cppBreakPattern,
+	"h":     cppBreakPattern,
+	"hh":    cppBreakPattern,
+	"hpp":   cppBreakPattern,
+	"hxx":   cppBreakPattern,
+	"cs":    regexp.MustCompile(`^(\s)*(public|protected)( (static|virtual|override|abstract|sealed|async|new|extern|unsafe|internal))*( [A-Za-z_][A-Za-z0-9_.<>,\[\]? ]*)? [A-Za-z_][A-Za-z0-9_]*(<[^(]+>)?\(`),
+	"py":    regexp.MustCompile(`^(\s)*(async )?def [A-Za-z][A-Za-z0-9_]*\(`),
+	"rb":    regexp.MustCompile(`^(\s)*def (self\.)?[A-Za-z_][A-Za-z0-9_]*[?!=]?(\(| |$)`),
+	"rs":    regexp.MustCompile(`^(\s)*pub(\([a-z: ]+\))?( (const|async|unsafe|extern "[^"]*"))* fn [A-Za-z_][A-Za-z0-9_]*(<[^(]*>)?\(`),
+	"swift": regexp.MustCompile(`^(\s)*(@[A-Za-z]+ )*((override|final|static|class|dynamic) )*(public|open)( (static|class|override|final|mutating|nonmutating|dynamic|required|convenience))* (func [A-Za-z_][A-Za-z0-9_]*|init[?!]?)(<[^(]*>)?\(`),
+	"ts":    tsBreakPattern,
+	"tsx":   tsBreakPattern,
+}
+
+var (
+	jsBreakPattern  = regexp.MustCompile(`^(\s)*(export( default)? )?(async )?function\*? ?[A-Za-z_$]*\(|^(\s)*(var )?[A-Za-z._]+(\s)*=(\s)*function \(|(\s)*[A-Za-z._]+(\s)*:(\s)*function \(|^(\s)*export (const|let|var) [A-Za-z_$][A-Za-z0-9_$]*(\s)*=(\s)*(async )?(function )?\(|(?P<factor>^(\s)*(static )?(async )?((get|set) )?\*?[A-Za-z_$][A-Za-z0-9_$]*\()([^()'"=]|=[^>])*\)(\s)*\{`)
+	cppBreakPattern = regexp.MustCompile(`^(\s)*((static|inline|virtual|explicit|constexpr|extern|friend) )*([A-Za-z_][A-Za-z0-9_:<>,]*[*&]* )+[*&]*[A-Za-z_~][A-Za-z0-9_:~]*\(`)
+	tsBreakPattern  = regexp.MustCompile(`^(\s)*export( default)?( async)? function [A-Za-z_$]+(<.+>)?\(|^(\s)*export (const|let) [A-Za-z_$]+(\s)*=(\s)*(async )?\(|^(\s)*public( static)?( async)? [A-Za-z_$]+(<.+>)?\(`)
+)
+
+// breakPattern returns the regex of a potential compatibility break associated
+// with type of the file
+func (f *file) breakPattern() (*regexp.Regexp, error) {
+	if f.customPattern != nil {
+		return f.customPattern, nil
+	}
+	pattern, known := breakPatterns[f.typeFile]
+	if !known {
+		return nil, errors.New("Unknown langage")
 	}
 
 	return pattern, nil
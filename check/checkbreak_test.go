@@ -0,0 +1,1114 @@
+package check
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTypeScriptRemovedExportedFunction(t *testing.T) {
+	breaks := compared(t, "ts",
+		"export function foo(a: number) {\n}\nexport function bar() {\n}\n",
+		"export function bar() {\n}\n")
+
+	assertExplanations(t, breaks, "Deletion of method")
+	if "export function foo(a: number) {" != breaks[0].Before {
+		t.Errorf("Unexpected deleted signature %s", breaks[0].Before)
+	}
+}
+
+func TestTypeScriptChangedParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+	}{
+		{"function", "export function foo(a: number, b: string) {\n}\n", "export function foo(a: number) {\n}\n"},
+		{"arrow function", "export const foo = (a: number, b: string) => a;\n", "export const foo = (a: number) => a;\n"},
+		{"public method", "class A {\n  public foo(a: number, b: string) {\n  }\n}\n", "class A {\n  public foo(a: number) {\n  }\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "ts", tt.before, tt.after), "Deletion of parameter")
+		})
+	}
+}
+
+func TestTypeScriptIgnoresPrivateMembers(t *testing.T) {
+	breaks := compared(t, "tsx",
+		"class A {\n  private foo(a: number) {\n  }\n  protected bar(a: number) {\n  }\n}\nfunction baz(a: number) {\n}\n",
+		"class A {\n  private foo() {\n  }\n  protected bar() {\n  }\n}\nfunction baz() {\n}\n")
+
+	assertExplanations(t, breaks)
+}
+
+func TestJavaScriptClassesAndExports(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"removed class method", "class A {\n  greet(a) {\n  }\n  bye() {\n  }\n}\n", "class A {\n  greet(a) {\n  }\n}\n", []string{"Deletion of method"}},
+		{"export default", "export default function foo(a) {\n}\n", "export default function foo() {\n}\n", []string{"Deletion of parameter"}},
+		{"removed calls and statements", "export function foo(a) {\n  bar(a);\n  this.baz(a).then(() => {\n  });\n  if (a) {\n  }\n}\n", "export function foo(a) {\n}\n", nil},
+		{"removed callbacks", "setTimeout(function () {\n}, 10);\ndescribe('x', function() {\n  it(\"works\", () => {\n  });\n});\nrun((a) => {\n});\n", "", nil},
+		{"method default value", "class A {\n  greet(a, b = 1) {\n  }\n}\n", "class A {\n  greet(a) {\n  }\n}\n", []string{"Deletion of default parameter"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "js", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestJavaScriptModuleExtensions(t *testing.T) {
+	for _, language := range []string{"mjs", "cjs", "jsx"} {
+		t.Run(language, func(t *testing.T) {
+			breaks := compared(t, language, "export function foo(a) {\n}\nexport function bar() {\n}\n", "export function foo(a) {\n}\n")
+
+			assertExplanations(t, breaks, "Deletion of method")
+		})
+	}
+}
+
+func TestJSXComponents(t *testing.T) {
+	breaks := compared(t, "jsx",
+		"export default function App(props) {\n  return <div />;\n}\nexport const Button = (props) => {\n  return <button />;\n};\n",
+		"export default function App() {\n  return <div />;\n}\n")
+
+	assertExplanations(t, breaks, "Deletion of parameter", "Deletion of method")
+}
+
+func TestPythonParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"removed parameter", "def foo(a, b):\n    pass\n", "def foo(a):\n    pass\n", []string{"Deletion of parameter"}},
+		{"reordered keyword arguments", "def foo(a, b=1, c=2):\n    pass\n", "def foo(a, c=2, b=1):\n    pass\n", nil},
+		{"added args and kwargs", "def foo(a):\n    pass\n", "def foo(a, *args, **kwargs):\n    pass\n", nil},
+		{"added keyword-only parameter", "def foo(a):\n    pass\n", "def foo(a, *, b=1):\n    pass\n", nil},
+		{"method", "class A:\n    def foo(self, a):\n        pass\n", "class A:\n    def foo(self):\n        pass\n", []string{"Deletion of parameter"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "py", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestPythonIgnoresPrivateFunctions(t *testing.T) {
+	breaks := compared(t, "py",
+		"def _foo(a):\n    pass\nclass A:\n    def __bar(self, a):\n        pass\n",
+		"def _foo():\n    pass\nclass A:\n    def __bar(self):\n        pass\n")
+
+	assertExplanations(t, breaks)
+}
+
+func TestRubyParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"removed positional parameter", "def foo(a, b)\nend\n", "def foo(a)\nend\n", []string{"Deletion of parameter"}},
+		{"positional parameter made keyword", "def foo(a, b)\nend\n", "def foo(a, b: 1)\nend\n", []string{"Parameter made keyword"}},
+		{"keyword parameter made positional", "def foo(a, b: 1)\nend\n", "def foo(a, b)\nend\n", []string{"Keyword parameter made positional"}},
+		{"reordered keyword parameters", "def foo(a:, b: 2)\nend\n", "def foo(b: 2, a:)\nend\n", nil},
+		{"added keyword parameter with default", "def foo(a)\nend\n", "def foo(a, b: 1)\nend\n", nil},
+		{"added required keyword parameter", "def foo(a)\nend\n", "def foo(a, b:)\nend\n", []string{"Adding a parameter without default value"}},
+		{"without parenthesis", "def self.foo a, b\nend\n", "def self.foo a\nend\n", []string{"Deletion of parameter"}},
+		{"removed method without parameter", "def foo\nend\n\ndef bar(a)\nend\n", "def bar(a)\nend\n", []string{"Deletion of method"}},
+		{"removed last parameter and parenthesis", "def foo(a)\nend\n", "def foo\nend\n", []string{"Deletion of parameter"}},
+		{"added parameter with default", "def foo\nend\n", "def foo(a = 1)\nend\n", nil},
+		{"other method with the name as prefix", "def foo\nend\n", "def foobar\nend\n", []string{"Deletion of method"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "rb", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestRustPublicFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"removed parameter", "pub fn foo(a: i32, b: i32) -> i32 {\n}\n", "pub fn foo(a: i32) -> i32 {\n}\n", []string{"Deletion of parameter"}},
+		{"crate visible", "pub(crate) fn foo(a: i32, b: i32) {\n}\n", "pub(crate) fn foo(a: i32) {\n}\n", []string{"Deletion of parameter"}},
+		{"generic bounds", "pub fn foo<T: Clone>(a: T, b: T) {\n}\n", "pub fn foo<T: Clone>(a: T) {\n}\n", []string{"Deletion of parameter"}},
+		{"private helper renamed", "fn helper(a: i32) {\n}\n", "fn assist(a: i32) {\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "rs", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestKotlinFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"dropped defaulted parameter", "fun foo(a: Int, b: Int = 1) {\n}\n", "fun foo(a: Int) {\n}\n", []string{"Deletion of default parameter"}},
+		{"member function", "class A {\n    fun foo(a: Int, b: Int) {\n    }\n}\n", "class A {\n    fun foo(a: Int) {\n    }\n}\n", []string{"Deletion of parameter"}},
+		{"private function renamed", "private fun foo(a: Int) {\n}\n", "private fun bar(a: Int) {\n}\n", nil},
+		{"internal function changed", "internal fun foo(a: Int) {\n}\n", "internal fun foo() {\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "kt", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestSwiftParameterLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"external label changed", "public func move(to point: Point) {\n}\n", "public func move(toward point: Point) {\n}\n", []string{"Parameter label changed"}},
+		{"open function", "open func move(to point: Point, by: Int) {\n}\n", "open func move(to point: Point) {\n}\n", []string{"Deletion of parameter"}},
+		{"internal name changed", "public func move(to point: Point) {\n}\n", "public func move(to target: Point) {\n}\n", nil},
+		{"private function", "private func move(to point: Point) {\n}\n", "private func move(toward point: Point) {\n}\n", nil},
+		{"fileprivate function", "fileprivate func move(to point: Point) {\n}\n", "fileprivate func move() {\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "swift", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestCppFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"removed parameter", "cpp", "int Foo::bar(int a, int b) {\n}\n", "int Foo::bar(int a) {\n}\n", []string{"Deletion of parameter"}},
+		{"declaration", "hpp", "void bar(const Foo *foo, int a);\n", "void bar(const Foo *foo);\n", []string{"Deletion of parameter"}},
+		{"removed function", "cc", "void bar(int a) {\n}\n\nvoid baz() {\n}\n", "void baz() {\n}\n", []string{"Deletion of method"}},
+		{"function returning a struct", "c", "struct foo *make(int a, int b) {\n}\n", "struct foo *make(int a) {\n}\n", []string{"Deletion of parameter"}},
+		{"removed function returning a struct", "c", "struct foo *make(int a) {\n}\n", "", []string{"Deletion of method"}},
+		{"static function returning a struct", "c", "static struct foo *make(int a) {\n}\n", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestCppParameterTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"changed type", "cpp", "int Foo::bar(int a) {\n}\n", "int Foo::bar(long a) {\n}\n", []string{"Parameter type changed"}},
+		{"pointer made reference", "hpp", "void bar(const Foo *foo);\n", "void bar(const Foo &foo);\n", []string{"Parameter type changed"}},
+		{"renamed parameter", "cc", "void bar(const Foo& foo) {\n}\n", "void bar(const Foo &other) {\n}\n", nil},
+		{"unnamed parameter", "h", "void bar(int, char *);\n", "void bar(int, const char *);\n", []string{"Parameter type changed"}},
+		{"array bounds", "cxx", "void bar(int values[4]) {\n}\n", "void bar(int values[8]) {\n}\n", []string{"Parameter type changed"}},
+		{"c function", "c", "int bar(int a, int b) {\n}\n", "int bar(int a) {\n}\n", []string{"Deletion of parameter"}},
+		{"c static function", "c", "static int bar(int a, int b) {\n}\n", "static int bar(int a) {\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestCppIgnoresCalls(t *testing.T) {
+	breaks := compared(t, "cpp",
+		"int main() {\n  return bar(1);\n}\n",
+		"int main() {\n  return bar(2);\n}\n")
+
+	assertExplanations(t, breaks)
+}
+
+func TestCppFunctionBodies(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"local construction", "cpp", "int bar(const char *buf) {\n  std::string s(buf);\n  return 0;\n}\n", "int bar(const char *buf) {\n  std::string s(buf, 4);\n  return 0;\n}\n", nil},
+		{"removed local construction", "cc", "void bar(int a)\n{\n  if (a) {\n    Foo foo(a, 1);\n  }\n}\n", "void bar(int a)\n{\n}\n", nil},
+		{"inline member function", "hpp", "namespace ns {\nclass A {\n  void bar(int a) {\n    Foo foo(a);\n  }\n};\n}\n", "namespace ns {\nclass A {\n  void bar(int a) {\n  }\n};\n}\n", nil},
+		{"function after a body", "cpp", "namespace ns {\nint foo() {\n  Foo f(1);\n}\n\nint bar(int a, int b) {\n}\n}\n", "namespace ns {\nint foo() {\n}\n\nint bar(int a) {\n}\n}\n", []string{"Deletion of parameter"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestGoReturnTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"single type", "func (s S) Foo() int {\n}\n", "func (s S) Foo() string {\n}\n", []string{"Return type changed"}},
+		{"several types", "func (s S) Foo() (int, error) {\n}\n", "func (s S) Foo() (string, error) {\n}\n", []string{"Return type changed"}},
+		{"unchanged", "func (s S) Foo() (int, error) {\n}\n", "func (s S) Foo() (int,error) {\n}\n", nil},
+		{"method", "func (s *S) Foo() int {\n}\n", "func (s *S) Foo() {\n}\n", []string{"Return type changed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "go", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestReturnTypeNarrowing(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"Go slice to array", "go", "func Items() []Item {\n}\n", "func Items() [2]Item {\n}\n", []string{"Return type narrowed"}},
+		{"Go array to slice", "go", "func Items() [2]Item {\n}\n", "func Items() []Item {\n}\n", []string{"Return type widened"}},
+		{"Java interface to implementation", "java", "public class A {\n    public List<X> items() {\n    }\n}\n", "public class A {\n    public ArrayList<X> items() {\n    }\n}\n", []string{"Return type narrowed"}},
+		{"Java implementation to interface", "java", "public class A {\n    public ArrayList<X> items() {\n    }\n}\n", "public class A {\n    public List<X> items() {\n    }\n}\n", []string{"Return type widened"}},
+		{"Java collection to list", "java", "public class A {\n    public Collection<X> items() {\n    }\n}\n", "public class A {\n    public List<X> items() {\n    }\n}\n", []string{"Return type narrowed"}},
+		{"Java unrelated collections", "java", "public class A {\n    public List<X> items() {\n    }\n}\n", "public class A {\n    public Set<X> items() {\n    }\n}\n", []string{"Return type changed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestGoGenerics(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"added type parameter", "func Map[T any](s []T) {\n}\n", "func Map[T any, U any](s []T) {\n}\n", []string{"Type parameters changed"}},
+		{"tightened constraint", "func Map[T any](s []T) {\n}\n", "func Map[T comparable](s []T) {\n}\n", []string{"Type parameters changed"}},
+		{"removed function", "func Map[T any](s []T) {\n}\n", "", []string{"Deletion of method"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "go", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestGoExportedNames(t *testing.T) {
+	breaks := compared(t, "go",
+		"func Foo2(a int) {\n}\nfunc Get_x(a int) {\n}\nfunc private(a int) {\n}\n",
+		"")
+
+	assertExplanations(t, breaks, "Deletion of method", "Deletion of method")
+}
+
+func TestGoVariadicParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"made variadic", "func Log(msg string) {\n}\n", "func Log(msg ...string) {\n}\n", []string{"Parameter made variadic"}},
+		{"no longer variadic", "func Log(msg ...string) {\n}\n", "func Log(msg string) {\n}\n", []string{"Variadic parameter removed"}},
+		{"variadic removed", "func Log(format string, args ...interface{}) {\n}\n", "func Log(format string) {\n}\n", []string{"Variadic parameter removed"}},
+		{"variadic added", "func Log(format string) {\n}\n", "func Log(format string, args ...interface{}) {\n}\n", nil},
+		{"variadic type changed", "func Log(args ...int) {\n}\n", "func Log(args ...string) {\n}\n", []string{"Parameter type changed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "go", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestGoInterfaceMethods(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"removed method", "type Store interface {\n\tGet(ctx context.Context) error\n\tPut(ctx context.Context) error\n}\n", "type Store interface {\n\tGet(ctx context.Context) error\n}\n", []string{"Deletion of method"}},
+		{"changed method", "type Store interface {\n\tGet(ctx context.Context) error\n}\n", "type Store interface {\n\tGet(ctx context.Context, key string) error\n}\n", []string{"Adding a parameter without default value"}},
+		{"unexported interface", "type store interface {\n\tGet(ctx context.Context) error\n}\n", "type store interface {\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "go", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestMethodsAddedToInterfaces(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"Go interface", "go", "type Store interface {\n\tGet(ctx context.Context) error\n}\n", "type Store interface {\n\tGet(ctx context.Context) error\n\tPut(ctx context.Context) error\n}\n", []string{"Method added to interface"}},
+		{"Go unexported interface", "go", "type store interface {\n\tGet(ctx context.Context) error\n}\n", "type store interface {\n\tGet(ctx context.Context) error\n\tPut(ctx context.Context) error\n}\n", nil},
+		{"Go new interface", "go", "", "type Store interface {\n\tGet(ctx context.Context) error\n}\n", nil},
+		{"Java interface", "java", "public interface Store {\n    void get(String key);\n}\n", "public interface Store {\n    void get(String key);\n    void put(String key);\n}\n", []string{"Method added to interface"}},
+		{"Java default method", "java", "public interface Store {\n    void get(String key);\n}\n", "public interface Store {\n    void get(String key);\n    default void put(String key) {\n    }\n}\n", nil},
+		{"Java abstract class", "java", "public abstract class Store {\n    public abstract void get(String key);\n}\n", "public abstract class Store {\n    public abstract void get(String key);\n    public abstract void put(String key);\n    public void close() {\n    }\n}\n", []string{"Method added to interface"}},
+		{"PHP interface", "php", "<?php\ninterface Store\n{\n    public function get($key);\n}\n", "<?php\ninterface Store\n{\n    public function get($key);\n    public function put($key);\n}\n", []string{"Method added to interface"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestGoReceiverTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"value to pointer", "func (s Server) Foo(a int) {\n}\n", "func (s *Server) Foo(a int) {\n}\n", []string{"Receiver type changed"}},
+		{"pointer to value", "func (s *Server) Foo(a int) {\n}\n", "func (s Server) Foo(a int) {\n}\n", []string{"Receiver type changed"}},
+		{"receiver renamed", "func (s *Server) Foo(a int) {\n}\n", "func (srv *Server) Foo(a int) {\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "go", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestBreaksSkipsUnmatchedDeletions(t *testing.T) {
+	f := file{
+		name:     "foo.go",
+		typeFile: "go",
+		diff: diff{
+			deletions: []string{"\tbar(a, b)", "func Foo(a int) {"},
+			addings:   []string{"\tbar(a)"},
+		},
+	}
+
+	methods, err := f.breaks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if 1 != len(*methods) || "func Foo(a int) {" != (*methods)[0].before {
+		t.Errorf("Expected only the deletion of Foo, got %v", *methods)
+	}
+}
+
+func TestMethodRenames(t *testing.T) {
+	tests := []struct {
+		name     string
+		after    string
+		expected []string
+	}{
+		{"clean rename", "package a\n\nfunc GetUser(id int, name string) error {\n}\n", []string{"Method renamed"}},
+		{"unrelated name", "package a\n\nfunc Close(id int, name string) error {\n}\n", []string{"Deletion of method"}},
+		{"other parameters", "package a\n\nfunc GetUser(id int) error {\n}\n", []string{"Deletion of method"}},
+	}
+	before := "package a\n\nfunc FetchUser(id int, name string) error {\n}\n"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "go", before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestReindentedSignatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+	}{
+		{"tabs to spaces", "java", "public class A {\n\tpublic void foo(int a) {\n\t}\n}\n", "public class A {\n    public void foo(int a) {\n    }\n}\n"},
+		{"inner spaces", "java", "public class A {\n    public void foo(int a, int b) {\n    }\n}\n", "public class A {\n    public void foo(int a,int b) {\n    }\n}\n"},
+		{"nested", "js", "export function foo(a) {\n}\n", "  export function foo(a) {\n  }\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after))
+		})
+	}
+}
+
+func TestDifferences(t *testing.T) {
+	tests := []struct {
+		name            string
+		before          []string
+		after           []string
+		expectedDeleted []string
+		expectedAdded   []string
+	}{
+		{"inserted in the middle", []string{"a int", "b int", "c int"}, []string{"a int", "x int", "b int", "c int"}, nil, []string{"x int"}},
+		{"inserted at the front", []string{"a int", "b int"}, []string{"x int", "a int", "b int"}, nil, []string{"x int"}},
+		{"removed in the middle", []string{"a int", "b int", "c int"}, []string{"a int", "c int"}, []string{"b int"}, nil},
+		{"replaced", []string{"a int", "b int"}, []string{"a int", "b string"}, []string{"b int"}, []string{"b string"}},
+		{"unchanged", []string{"a int"}, []string{"a int"}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted, added := differences(tt.before, tt.after)
+			if !reflect.DeepEqual(deleted, tt.expectedDeleted) || !reflect.DeepEqual(added, tt.expectedAdded) {
+				t.Errorf("Expected -%q +%q, got -%q +%q", tt.expectedDeleted, tt.expectedAdded, deleted, added)
+			}
+		})
+	}
+}
+
+func TestInsertedParameterIsASingleAdding(t *testing.T) {
+	breaks := compared(t, "go", "func Foo(a int, c int) {\n}\n", "func Foo(a int, b int, c int) {\n}\n")
+
+	assertExplanations(t, breaks, "Adding a parameter without default value")
+}
+
+func TestPHPReducedVisibility(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"public to private", "<?php\nclass A {\n    public function foo($a) {\n    }\n}\n", "<?php\nclass A {\n    private function foo($a) {\n    }\n}\n", []string{"Reduced visibility (public -> private)"}},
+		{"public to protected", "<?php\nclass A {\n    public static function foo($a) {\n    }\n}\n", "<?php\nclass A {\n    protected static function foo($a) {\n    }\n}\n", []string{"Reduced visibility (public -> protected)"}},
+		{"private to public", "<?php\nclass A {\n    private function foo($a) {\n    }\n}\n", "<?php\nclass A {\n    public function foo($a) {\n    }\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "php", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestJavaKotlinReducedVisibility(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"java public to protected", "java", "public class A {\n    public void foo(int a) {\n    }\n}\n", "public class A {\n    protected void foo(int a) {\n    }\n}\n", []string{"Reduced visibility (public -> protected)"}},
+		{"java protected to package-private", "java", "public class A {\n    protected void foo(int a) {\n    }\n}\n", "public class A {\n    void foo(int a) {\n    }\n}\n", []string{"Reduced visibility (protected -> package-private)"}},
+		{"java protected to private", "java", "public class A {\n    protected static int foo(int a) {\n    }\n}\n", "public class A {\n    private static int foo(int a) {\n    }\n}\n", []string{"Reduced visibility (protected -> private)"}},
+		{"java public to private", "java", "public class A {\n    public void foo(int a) {\n    }\n}\n", "public class A {\n    private void foo(int a) {\n    }\n}\n", []string{"Reduced visibility (public -> private)"}},
+		{"java protected to public", "java", "public class A {\n    protected void foo(int a) {\n    }\n}\n", "public class A {\n    public void foo(int a) {\n    }\n}\n", nil},
+		{"kotlin public to protected", "kt", "open class A {\n    public fun foo(a: Int) {\n    }\n}\n", "open class A {\n    protected fun foo(a: Int) {\n    }\n}\n", []string{"Reduced visibility (public -> protected)"}},
+		{"kotlin default to internal", "kt", "class A {\n    fun foo(a: Int) {\n    }\n}\n", "class A {\n    internal fun foo(a: Int) {\n    }\n}\n", []string{"Reduced visibility (public -> internal)"}},
+		{"kotlin protected to private", "kt", "open class A {\n    protected fun foo(a: Int) {\n    }\n}\n", "open class A {\n    private fun foo(a: Int) {\n    }\n}\n", []string{"Reduced visibility (protected -> private)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestPHPTypeDeclarations(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"parameter type", "<?php\nclass A {\n    public function find(int $id): ?User\n    {\n    }\n}\n", "<?php\nclass A {\n    public function find(string $id): ?User\n    {\n    }\n}\n", []string{"Parameter type changed"}},
+		{"nullable return tightened", "<?php\nclass A {\n    public function find(int $id): ?User\n    {\n    }\n}\n", "<?php\nclass A {\n    public function find(int $id): User\n    {\n    }\n}\n", []string{"Return type changed"}},
+		{"parameter renamed", "<?php\nclass A {\n    public function find(int $id): ?User\n    {\n    }\n}\n", "<?php\nclass A {\n    public function find(int $key): ?User\n    {\n    }\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "php", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestMadeFinal(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"Java method made final", "java", "public class A {\n    public void foo(int a) {\n    }\n}\n", "public class A {\n    public final void foo(int a) {\n    }\n}\n", []string{"Made final/non-overridable"}},
+		{"Java method no longer final", "java", "public class A {\n    public final void foo(int a) {\n    }\n}\n", "public class A {\n    public void foo(int a) {\n    }\n}\n", nil},
+		{"Java class made final", "java", "public class A {\n}\n", "public final class A {\n}\n", []string{"Made final/non-overridable"}},
+		{"Java class made sealed", "java", "public class A {\n}\n", "public sealed class A permits B {\n}\n", []string{"Made final/non-overridable"}},
+		{"Kotlin method no longer open", "kt", "open class A {\n    open fun foo(a: Int) {\n    }\n}\n", "open class A {\n    fun foo(a: Int) {\n    }\n}\n", []string{"Made final/non-overridable"}},
+		{"Kotlin class made sealed", "kt", "open class A {\n}\n", "sealed class A {\n}\n", []string{"Made final/non-overridable"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestOverloadDeletions(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"Java first overload", "java", "public class A {\n    public void foo(int a) {\n    }\n    public void foo(String a) {\n    }\n}\n", "public class A {\n    public void foo(String a) {\n    }\n}\n", []string{"Deletion of overload"}},
+		{"Java last overload", "java", "public class A {\n    public void foo(String a) {\n    }\n    public void foo(int a) {\n    }\n}\n", "public class A {\n    public void foo(String a) {\n    }\n}\n", []string{"Deletion of overload"}},
+		{"Java overload changed", "java", "public class A {\n    public void foo(int a) {\n    }\n    public void foo(String a) {\n    }\n}\n", "public class A {\n    public void foo(int a, int b) {\n    }\n    public void foo(String a) {\n    }\n}\n", []string{"Adding a parameter without default value"}},
+		{"C# overload", "cs", "public class A\n{\n    public void Foo(int a)\n    {\n    }\n    public void Foo(string a, int b)\n    {\n    }\n}\n", "public class A\n{\n    public void Foo(string a, int b)\n    {\n    }\n}\n", []string{"Deletion of overload"}},
+		{"TypeScript overload", "ts", "export function foo(a: number): void;\nexport function foo(a: string): void;\nexport function foo(a: any): void {\n}\n", "export function foo(a: string): void;\nexport function foo(a: any): void {\n}\n", []string{"Deletion of overload"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestPHPInterfaceMethods(t *testing.T) {
+	before := "<?php\ninterface Handler\n{\n    public function handle(Request $r): Response;\n    public function close();\n}\n"
+	tests := []struct {
+		name     string
+		after    string
+		expected []string
+	}{
+		{"added parameter", "<?php\ninterface Handler\n{\n    public function handle(Request $r, array $options): Response;\n    public function close();\n}\n", []string{"Adding a parameter without default value"}},
+		{"parameter type", "<?php\ninterface Handler\n{\n    public function handle(ServerRequest $r): Response;\n    public function close();\n}\n", []string{"Parameter type changed"}},
+		{"return type", "<?php\ninterface Handler\n{\n    public function handle(Request $r): ?Response;\n    public function close();\n}\n", []string{"Return type changed"}},
+		{"removed method", "<?php\ninterface Handler\n{\n    public function handle(Request $r): Response;\n}\n", []string{"Deletion of method"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "php", before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestPHPModifiersOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"reordered", "<?php\nabstract class A\n{\n    abstract public function f($a);\n}\n", "<?php\nabstract class A\n{\n    public abstract function f($a);\n}\n", nil},
+		{"reordered static", "<?php\nclass A\n{\n    static public function f($a) {\n    }\n}\n", "<?php\nclass A\n{\n    public static function f($a) {\n    }\n}\n", nil},
+		{"reordered with removed parameter", "<?php\nabstract class A\n{\n    abstract public function f($a, $b);\n}\n", "<?php\nabstract class A\n{\n    public abstract function f($a);\n}\n", []string{"Deletion of parameter"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "php", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestJavaCheckedExceptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"added checked exception", "public class A {\n    public void save() {\n    }\n}\n", "public class A {\n    public void save() throws IOException {\n    }\n}\n", []string{"Added checked exception"}},
+		{"added unchecked exception", "public class A {\n    public void save() {\n    }\n}\n", "public class A {\n    public void save() throws IllegalStateException {\n    }\n}\n", nil},
+		{"removed checked exception", "public class A {\n    public void save() throws IOException, SQLException {\n    }\n}\n", "public class A {\n    public void save() throws IOException {\n    }\n}\n", nil},
+		{"added checked exception on its own line", "public class A {\n    public void save(String name)\n            throws IOException {\n    }\n}\n", "public class A {\n    public void save(String name)\n            throws IOException, SQLException {\n    }\n}\n", []string{"Added checked exception"}},
+		{"added checked exception over lines", "public class A {\n    public void save(String name)\n            throws IOException,\n            UncheckedIOException {\n    }\n}\n", "public class A {\n    public void save(String name)\n            throws IOException,\n            SQLException {\n    }\n}\n", []string{"Added checked exception"}},
+		{"throws clause wrapped", "public class A {\n    public void save(String name) throws IOException {\n    }\n}\n", "public class A {\n    public void save(String name)\n            throws IOException {\n    }\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "java", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestShebangType(t *testing.T) {
+	tests := map[string]string{
+		"#!/bin/bash":            "sh",
+		"#!/usr/bin/env bash":    "sh",
+		"#!/usr/bin/env python3": "py",
+		"#!/usr/bin/env -S node": "js",
+		"#!/usr/bin/perl":        "",
+		"echo foo":               "",
+	}
+	for line, expected := range tests {
+		if typeFile := shebangType(line); expected != typeFile {
+			t.Errorf("Expected %q for %s, got %q", expected, line, typeFile)
+		}
+	}
+}
+
+func TestExtensionlessScript(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{"bin/deploy": "#!/bin/bash\n\nfunction deploy() {\n}\n\nfunction rollback() {\n}\n"},
+		map[string]string{"bin/deploy": "#!/bin/bash\n\nfunction deploy() {\n}\n"})
+
+	results := analyzed(t, dir, "none.json")
+	assertExplanations(t, breaksOf(results, "bin/deploy"), "Deletion of method")
+}
+
+func TestParameterTypeChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"go", "go", "func Foo(x int) {\n}\n", "func Foo(x string) {\n}\n", []string{"Parameter type changed"}},
+		{"go grouped", "go", "func Foo(a, b int) {\n}\n", "func Foo(a int, b int) {\n}\n", nil},
+		{"go grouped type changed", "go", "func Foo(a, b int) {\n}\n", "func Foo(a int, b string) {\n}\n", []string{"Parameter type changed"}},
+		{"java", "java", "public class A {\n    public void foo(int x) {\n    }\n}\n", "public class A {\n    public void foo(String x) {\n    }\n}\n", []string{"Parameter type changed"}},
+		{"typescript", "ts", "export function foo(x: number) {\n}\n", "export function foo(x: string) {\n}\n", []string{"Parameter type changed"}},
+		{"typescript type dropped", "ts", "export function foo(x: number) {\n}\n", "export function foo(x) {\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestPairwiseParameterChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"second parameter type changed", "func Foo(a int, b int) {\n}\n", "func Foo(a int, b string) {\n}\n", []string{"Parameter type changed"}},
+		{"first parameter renamed, second type changed", "func Foo(a int, b int) {\n}\n", "func Foo(x int, b string) {\n}\n", []string{"Parameter type changed"}},
+		{"only names changed", "func Foo(a int, b int) {\n}\n", "func Foo(x int, y int) {\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "go", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestCompoundParameterChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"first removed, last added", "js", "function foo(a, b) {\n}\n", "function foo(b, other) {\n}\n", []string{"Parameter removed and parameter added (-a, +other)"}},
+		{"middle removed, last added", "go", "func Foo(a int, b string, c bool) {\n}\n", "func Foo(a int, c bool, d float64) {\n}\n", []string{"Parameter removed and parameter added (-b string, +d float64)"}},
+		{"typed parameters", "go", "func Foo(a int, b string) {\n}\n", "func Foo(b string, c bool) {\n}\n", []string{"Parameter removed and parameter added (-a int, +c bool)"}},
+		{"replaced in place", "py", "def foo(a, b):\n    pass\n", "def foo(a, c):\n    pass\n", []string{"Parameter renamed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestRenamedKeywordParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"Python", "py", "def f(user):\n    pass\n", "def f(account):\n    pass\n", []string{"Parameter renamed"}},
+		{"Python annotated", "py", "def f(user: str):\n    pass\n", "def f(account: str):\n    pass\n", []string{"Parameter renamed"}},
+		{"Python positional-only", "py", "def f(user, /):\n    pass\n", "def f(account, /):\n    pass\n", nil},
+		{"Kotlin", "kt", "fun f(user: String) {\n}\n", "fun f(account: String) {\n}\n", []string{"Parameter renamed"}},
+		{"Swift unlabelled", "swift", "public func f(_ user: String) {\n}\n", "public func f(_ account: String) {\n}\n", nil},
+		{"Go", "go", "func F(user string) {\n}\n", "func F(account string) {\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestDefaultValueChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"PHP default changed", "php", "<?php\nfunction f($x = 10) {\n}\n", "<?php\nfunction f($x = 20) {\n}\n", []string{"Default value changed"}},
+		{"PHP parameter renamed", "php", "<?php\nfunction f($x = 10) {\n}\n", "<?php\nfunction f($y = 10) {\n}\n", nil},
+		{"Python default changed", "py", "def f(a, x=10):\n    pass\n", "def f(a, x=20):\n    pass\n", []string{"Default value changed"}},
+		{"Python default reformatted", "py", "def f(a, x=10):\n    pass\n", "def f(a, x = 10):\n    pass\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestAddedDefaultParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"PHP one default", "php", "<?php\nfunction f($a) {\n}\n", "<?php\nfunction f($a, $b = 1) {\n}\n", nil},
+		{"PHP several defaults", "php", "<?php\nfunction f($a) {\n}\n", "<?php\nfunction f($a, $b = 1, $c = []) {\n}\n", nil},
+		{"PHP default then mandatory", "php", "<?php\nfunction f($a) {\n}\n", "<?php\nfunction f($a, $b = 1, $c) {\n}\n", []string{"Adding a parameter without default value"}},
+		{"Python default", "py", "def f(a):\n    pass\n", "def f(a, b=1):\n    pass\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestReorderedParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"PHP swap", "php", "<?php\nfunction f($a, $b) {\n}\n", "<?php\nfunction f($b, $a) {\n}\n", []string{"Parameters reordered"}},
+		{"Go swap", "go", "func F(a int, b string) {\n}\n", "func F(b string, a int) {\n}\n", []string{"Parameters reordered"}},
+		{"Python rotation", "py", "def f(a, b, c):\n    pass\n", "def f(c, a, b):\n    pass\n", []string{"Parameters reordered"}},
+		{"Ruby keywords", "rb", "def f(a:, b:)\nend\n", "def f(b:, a:)\nend\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestJoinSignature(t *testing.T) {
+	tests := []struct {
+		signature    string
+		continuation string
+		expected     string
+	}{
+		{"func Foo(", "a int,", "func Foo(a int,"},
+		{"func Foo(a int,", "b string,", "func Foo(a int, b string,"},
+		{"func Foo(a int, b string,", ") error {", "func Foo(a int, b string) error {"},
+		{"func Foo(a int", ") error {", "func Foo(a int) error {"},
+	}
+	for _, tt := range tests {
+		if joined := joinSignature(tt.signature, tt.continuation); tt.expected != joined {
+			t.Errorf("Expected %q, got %q", tt.expected, joined)
+		}
+	}
+}
+
+func TestWrappedSignatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"go lost parameter", "go", "func Foo(\n\ta int,\n\tb string,\n) error {\n}\n", "func Foo(\n\ta int,\n) error {\n}\n", []string{"Deletion of parameter"}},
+		{"go rewrapped", "go", "func Foo(\n\ta int,\n\tb string,\n) error {\n}\n", "func Foo(a int, b string) error {\n}\n", nil},
+		{"java lost parameter", "java", "public class A {\n    public void foo(int a,\n                    String b) {\n    }\n}\n", "public class A {\n    public void foo(int a) {\n    }\n}\n", []string{"Deletion of parameter"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestGoTypesAndFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"removed exported field", "type Foo struct {\n\tBar string\n\tBaz int\n}\n", "type Foo struct {\n\tBar string\n}\n", []string{"Deletion of field"}},
+		{"removed unexported field", "type Foo struct {\n\tBar string\n\tbaz int\n}\n", "type Foo struct {\n\tBar string\n}\n", nil},
+		{"removed exported type", "type Foo struct {\n\tBar string\n}\n", "", []string{"Public type removed", "Deletion of field"}},
+		{"removed unexported type", "type foo struct {\n\tBar string\n}\n", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "go", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestPublicTypeRemovals(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		expected []string
+	}{
+		{"Java public class", "java", "package a;\n\npublic class Foo {\n    public void foo() {\n    }\n}\n\nclass Bar {\n}\n", []string{"Deletion of method", "Public type removed"}},
+		{"C# public class", "cs", "namespace A\n{\n    public class Foo\n    {\n    }\n    internal class Bar\n    {\n    }\n}\n", []string{"Public type removed"}},
+		{"PHP class", "php", "<?php\nclass Foo\n{\n}\n", []string{"Public type removed"}},
+		{"TypeScript exported class", "ts", "export class Foo {\n}\nclass Bar {\n}\n", []string{"Public type removed"}},
+		{"Python public class", "py", "class Foo:\n    pass\n\nclass _Bar:\n    pass\n", []string{"Public type removed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, ""), tt.expected...)
+		})
+	}
+}
+
+func TestPublicConstants(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"PHP removed", "php", "<?php\nclass A\n{\n    const FOO = 1;\n    public const BAR = 2;\n    private const BAZ = 3;\n}\n", "<?php\nclass A\n{\n}\n", []string{"Public constant removed", "Public constant removed"}},
+		{"PHP changed", "php", "<?php\nclass A\n{\n    const FOO = 1;\n}\n", "<?php\nclass A\n{\n    const FOO = 2;\n}\n", []string{"Public constant changed"}},
+		{"PHP trait method", "php", "<?php\ntrait T\n{\n    public function foo($a)\n    {\n    }\n}\n", "<?php\ntrait T\n{\n    public function foo()\n    {\n    }\n}\n", []string{"Deletion of parameter"}},
+		{"Go removed", "go", "package a\n\nconst Foo = 1\nconst bar = 2\n", "package a\n", []string{"Public constant removed"}},
+		{"Go removed from a block", "go", "package a\n\nconst (\n\tFoo = 1\n\tbar = 2\n)\n", "package a\n\nconst (\n\tbar = 2\n)\n", []string{"Public constant removed"}},
+		{"Go changed", "go", "package a\n\nconst Foo = 1\n", "package a\n\nconst Foo = 2\n", []string{"Public constant changed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestMutabilityContractChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"rust borrowed mutably", "rs", "impl Foo {\n    pub fn bar(&self, a: i32) {\n    }\n}\n", "impl Foo {\n    pub fn bar(&mut self, a: i32) {\n    }\n}\n", []string{"Mutability contract changed (&self -> &mut self)"}},
+		{"rust taken", "rs", "impl Foo {\n    pub fn bar(&mut self) {\n    }\n}\n", "impl Foo {\n    pub fn bar(self) {\n    }\n}\n", []string{"Mutability contract changed (&mut self -> self)"}},
+		{"rust with lifetime", "rs", "impl Foo {\n    pub fn bar(&'a self) {\n    }\n}\n", "impl Foo {\n    pub fn bar(&'a mut self) {\n    }\n}\n", []string{"Mutability contract changed (&self -> &mut self)"}},
+		{"rust borrowed immutably", "rs", "impl Foo {\n    pub fn bar(&mut self) {\n    }\n}\n", "impl Foo {\n    pub fn bar(&self) {\n    }\n}\n", nil},
+		{"c++ const dropped", "cpp", "int Foo::bar(int a) const {\n}\n", "int Foo::bar(int a) {\n}\n", []string{"Mutability contract changed (const dropped)"}},
+		{"c++ const added", "cpp", "int Foo::bar(int a) {\n}\n", "int Foo::bar(int a) const {\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestCommentChangesHaveNoBreak(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+	}{
+		{"go line comment", "go", "// Foo does foo(a int)\nfunc Foo(a int) {\n}\n", "// Foo does foo(a int, b int)\nfunc Foo(a int) {\n}\n"},
+		{"java block comment", "java", "public class A {\n    /*\n     * public void old(int a) {\n     */\n    public void foo() {\n    }\n}\n", "public class A {\n    /*\n     * Foo\n     */\n    public void foo() {\n    }\n}\n"},
+		{"python comment", "py", "# def foo(a, b):\ndef foo(a):\n    pass\n", "# def foo(a):\ndef foo(a):\n    pass\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after))
+		})
+	}
+}
+
+func TestGoMethodConversions(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"function to method", "package a\n\nfunc Parse(s string) error {\n}\n", "package a\n\nfunc (p *Parser) Parse(s string) error {\n}\n", []string{"Function converted to method"}},
+		{"method to function", "package a\n\nfunc (p *Parser) Parse(s string) error {\n}\n", "package a\n\nfunc Parse(s string) error {\n}\n", []string{"Method converted to function"}},
+		{"other name", "package a\n\nfunc Parse(s string) error {\n}\n", "package a\n\nfunc (p *Parser) Decode(s string) error {\n}\n", []string{"Deletion of method"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "go", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestRemovedWithoutPriorDeprecation(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"go undeprecated", "foo.go", "package a\n\n// Foo does foo\nfunc Foo(a int) {\n}\n\nfunc Bar() {\n}\n", "package a\n\nfunc Bar() {\n}\n", []string{"Removed without prior deprecation"}},
+		{"go deprecated", "foo.go", "package a\n\n// Foo does foo\n//\n// Deprecated: use Bar\nfunc Foo(a int) {\n}\n\nfunc Bar() {\n}\n", "package a\n\nfunc Bar() {\n}\n", []string{"Deletion of method"}},
+		{"java undeprecated", "A.java", "public class A {\n    public void foo(int a) {\n    }\n\n    public void bar() {\n    }\n}\n", "public class A {\n    public void bar() {\n    }\n}\n", []string{"Removed without prior deprecation"}},
+		{"java deprecated", "A.java", "public class A {\n    @Deprecated\n    public void foo(int a) {\n    }\n\n    public void bar() {\n    }\n}\n", "public class A {\n    public void bar() {\n    }\n}\n", []string{"Deletion of method"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t,
+				map[string]string{"deprecation.json": `{"requireDeprecation": true}`, tt.filename: tt.before},
+				map[string]string{tt.filename: tt.after})
+			assertExplanations(t, breaksOf(analyzed(t, dir, "deprecation.json"), tt.filename), tt.expected...)
+			// Only required when configured
+			assertExplanations(t, breaksOf(analyzed(t, dir, "none.json"), tt.filename), "Deletion of method")
+		})
+	}
+}
+
+func TestTypeAliases(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"go parameter", "foo.go", "package a\n\nfunc F(x MyInt) {\n}\n", "package a\n\nfunc F(x int) {\n}\n", nil},
+		{"go return type", "foo.go", "package a\n\nfunc F(x int) MyInt {\n}\n", "package a\n\nfunc F(x int) int {\n}\n", nil},
+		{"go named like its alias", "foo.go", "package a\n\nfunc MyInt(x int) {\n}\n", "package a\n\nfunc MyInt(x int, y int) {\n}\n", []string{"Adding a parameter without default value"}},
+		{"go other type", "foo.go", "package a\n\nfunc F(x MyInt) {\n}\n", "package a\n\nfunc F(x string) {\n}\n", []string{"Parameter type changed"}},
+		{"java qualified", "A.java", "public class A {\n    public void f(Ids ids) {\n    }\n}\n", "public class A {\n    public void f(java.util.List<Integer> ids) {\n    }\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t,
+				map[string]string{"aliases.json": `{"aliases": {"MyInt": "int", "Ids": "java.util.List<Integer>"}}`, tt.filename: tt.before},
+				map[string]string{tt.filename: tt.after})
+			assertExplanations(t, breaksOf(analyzed(t, dir, "aliases.json"), tt.filename), tt.expected...)
+		})
+	}
+}
+
+func TestEnumValueRemovals(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"typescript removed", "ts", "export enum Color {\n    Red,\n    Green,\n    Blue,\n}\n", "export enum Color {\n    Red,\n    Blue,\n}\n", []string{"Enum value removed"}},
+		{"typescript initialized removed", "ts", "export enum Color {\n    Red = 'red',\n    Green = 'green',\n}\n", "export enum Color {\n    Red = 'red',\n}\n", []string{"Enum value removed"}},
+		{"typescript added", "ts", "export enum Color {\n    Red,\n}\n", "export enum Color {\n    Red,\n    Green,\n}\n", nil},
+		{"typescript private enum", "ts", "enum Color {\n    Red,\n    Green,\n}\n", "enum Color {\n    Red,\n}\n", nil},
+		{"java removed", "java", "public enum Color {\n    RED,\n    GREEN,\n    BLUE;\n}\n", "public enum Color {\n    RED,\n    BLUE;\n}\n", []string{"Enum value removed"}},
+		{"php removed", "php", "<?php\nenum Suit: string\n{\n    case Hearts = 'H';\n    case Spades = 'S';\n}\n", "<?php\nenum Suit: string\n{\n    case Hearts = 'H';\n}\n", []string{"Enum value removed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, tt.language, tt.before, tt.after), tt.expected...)
+		})
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	dir := newRepo(t, map[string]string{"foo.go": "package foo\n"}, map[string]string{})
+	missing := filepath.Join(dir, "missing")
+	file := filepath.Join(dir, "foo.go")
+
+	tests := []struct {
+		name       string
+		path       string
+		startPoint string
+		endPoint   string
+		target     error
+		other      error
+		message    string
+	}{
+		{"missing path", missing, "start", "HEAD", ErrPathNotFound, ErrRefNotFound, "Path " + missing + " doesn't exist"},
+		{"file path", file, "start", "HEAD", ErrPathNotFound, ErrRefNotFound, "Path " + file + " doesn't exist"},
+		{"unknown start point", dir, "v9.9.9", "HEAD", ErrRefNotFound, ErrPathNotFound, "The object v9.9.9 doesn't exist"},
+		{"unknown end point", dir, "start", "unknown", ErrRefNotFound, ErrPathNotFound, "The object unknown doesn't exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Init(tt.path, tt.startPoint, tt.endPoint, "none.json")
+			if !errors.Is(err, tt.target) {
+				t.Errorf("Expected %q to match %q", err, tt.target)
+			}
+			if errors.Is(err, tt.other) {
+				t.Errorf("Expected %q not to match %q", err, tt.other)
+			}
+			if err != nil && tt.message != err.Error() {
+				t.Errorf("Expected %q, got %q", tt.message, err.Error())
+			}
+		})
+	}
+}
+
+func TestCSharpMethods(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected []string
+	}{
+		{"removed parameter", "public class A\n{\n    public int Foo(int a, int b)\n    {\n    }\n}\n", "public class A\n{\n    public int Foo(int a)\n    {\n    }\n}\n", []string{"Deletion of parameter"}},
+		{"generic method", "public class A\n{\n    public static List<T> Map<T>(List<T> items, Func<T, T> f)\n    {\n    }\n}\n", "public class A\n{\n    public static List<T> Map<T>(List<T> items)\n    {\n    }\n}\n", []string{"Deletion of parameter"}},
+		{"removed method", "public class A\n{\n    protected virtual void Foo()\n    {\n    }\n}\n", "public class A\n{\n}\n", []string{"Deletion of method"}},
+		{"private method", "public class A\n{\n    private void Foo(int a)\n    {\n    }\n}\n", "public class A\n{\n    private void Foo()\n    {\n    }\n}\n", nil},
+		{"return type changed", "public class A\n{\n    public int Foo(int a)\n    {\n    }\n}\n", "public class A\n{\n    public string Foo(int a)\n    {\n    }\n}\n", []string{"Return type changed"}},
+		{"generic return type changed", "public class A\n{\n    public async Task<int> Foo(int a)\n    {\n    }\n}\n", "public class A\n{\n    public async Task<long> Foo(int a)\n    {\n    }\n}\n", []string{"Return type changed"}},
+		{"generic method return type changed", "public class A\n{\n    public static List<T> Map<T>(List<T> items)\n    {\n    }\n}\n", "public class A\n{\n    public static IList<T> Map<T>(List<T> items)\n    {\n    }\n}\n", []string{"Return type widened"}},
+		{"made private", "public class A\n{\n    public int Foo(int a)\n    {\n    }\n}\n", "public class A\n{\n    private int Foo(int a)\n    {\n    }\n}\n", []string{"Reduced visibility (public -> private)"}},
+		{"made protected", "public class A\n{\n    public int Foo(int a)\n    {\n    }\n}\n", "public class A\n{\n    protected int Foo(int a)\n    {\n    }\n}\n", []string{"Reduced visibility (public -> protected)"}},
+		{"modifier dropped", "public class A\n{\n    public int Foo(int a)\n    {\n    }\n}\n", "public class A\n{\n    int Foo(int a)\n    {\n    }\n}\n", []string{"Reduced visibility (public -> private)"}},
+		{"made public", "public class A\n{\n    protected int Foo(int a)\n    {\n    }\n}\n", "public class A\n{\n    public int Foo(int a)\n    {\n    }\n}\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertExplanations(t, compared(t, "cs", tt.before, tt.after), tt.expected...)
+		})
+	}
+}
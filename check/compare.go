@@ -0,0 +1,120 @@
+package check
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CompareFiles returns potentials compatibility breaks between two versions
+// of a file, without git. language is the type of the files (`go`, `php`…)
+func CompareFiles(oldPath string, newPath string, language string) ([]MethodBreak, error) {
+	f := file{
+		name:     newPath,
+		status:   "M",
+		typeFile: strings.ToLower(strings.TrimPrefix(language, ".")),
+	}
+	if !f.isTypeSupported() {
+		return nil, fmt.Errorf("Unknown langage %s", language)
+	}
+	before, err := readLines(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	after, err := readLines(newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := f.changes(unifiedDiff(before, after))
+	if err != nil {
+		return nil, err
+	}
+	f.diff = *diff
+	methods, err := f.breaks()
+	if err != nil {
+		return nil, err
+	}
+
+	return methodBreaks(*methods), nil
+}
+
+// readLines reads the lines of a file
+func readLines(filename string) ([]string, error) {
+	content, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(content)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return lines, scanner.Err()
+}
+
+// maxLineSize bounds the length of a line read, minified files aside
+const maxLineSize = 1024 * 1024
+
+// unifiedDiff synthesizes the lines of a diff between two slices of lines,
+// with full context, as `git diff -U` would. Common lines are aligned on
+// their longest common subsequence
+func unifiedDiff(before []string, after []string) []string {
+	// Common prefix and suffix are context, no need to align them
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	middleBefore := before[prefix : len(before)-suffix]
+	middleAfter := after[prefix : len(after)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of middleBefore[i:] and middleAfter[j:]
+	lcs := make([][]int, len(middleBefore)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(middleAfter)+1)
+	}
+	for i := len(middleBefore) - 1; i >= 0; i-- {
+		for j := len(middleAfter) - 1; j >= 0; j-- {
+			if middleBefore[i] == middleAfter[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	diffLines := make([]string, 0, len(before)+len(after))
+	for _, line := range before[:prefix] {
+		diffLines = append(diffLines, " "+line)
+	}
+	i, j := 0, 0
+	for i < len(middleBefore) || j < len(middleAfter) {
+		if i < len(middleBefore) && j < len(middleAfter) && middleBefore[i] == middleAfter[j] {
+			diffLines = append(diffLines, " "+middleBefore[i])
+			i++
+			j++
+		} else if j == len(middleAfter) || (i < len(middleBefore) && lcs[i+1][j] >= lcs[i][j+1]) {
+			diffLines = append(diffLines, "-"+middleBefore[i])
+			i++
+		} else {
+			diffLines = append(diffLines, "+"+middleAfter[j])
+			j++
+		}
+	}
+	for _, line := range before[len(before)-suffix:] {
+		diffLines = append(diffLines, " "+line)
+	}
+
+	return diffLines
+}
@@ -0,0 +1,48 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareFiles(t *testing.T) {
+	breaks := compared(t, "go",
+		"package foo\n\nfunc Foo(a int) {\n}\n\nfunc Bar(b string) {\n}\n",
+		"package foo\n\nfunc Foo(a int) {\n}\n")
+
+	expected := []MethodBreak{{
+		Before:       "func Bar(b string) {",
+		CommonFactor: "func Bar(",
+		Explanation:  "Deletion of method",
+		Severity:     Hard,
+		Line:         6,
+	}}
+	if !reflect.DeepEqual(expected, breaks) {
+		t.Errorf("Expected %v, got %v", expected, breaks)
+	}
+}
+
+func TestCompareFilesErrors(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "foo.go")
+	writeFile(t, existing, "package foo\n")
+
+	if _, err := CompareFiles(existing, existing, "cobol"); err == nil || !strings.Contains(err.Error(), "Unknown langage cobol") {
+		t.Errorf("Expected an unknown langage, got %v", err)
+	}
+	if _, err := CompareFiles(filepath.Join(dir, "missing.go"), existing, "go"); !os.IsNotExist(err) {
+		t.Errorf("Expected a missing file, got %v", err)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	diffLines := unifiedDiff([]string{"a", "b", "c", "d"}, []string{"a", "x", "c", "d", "e"})
+
+	expected := []string{" a", "-b", "+x", " c", " d", "+e"}
+	if !reflect.DeepEqual(expected, diffLines) {
+		t.Errorf("Expected %q, got %q", expected, diffLines)
+	}
+}
@@ -2,30 +2,415 @@ package check
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
 )
 
+// exclusion are the criteria excluding files, by path prefix, glob or regex
+type exclusion struct {
+	Path  []string `json:"path" yaml:"path" toml:"path"`
+	Glob  []string `json:"glob" yaml:"glob" toml:"glob"`
+	Regex []string `json:"regex" yaml:"regex" toml:"regex"`
+}
+
+// override are settings of a directory, on top of the config ones
+type override struct {
+	// Excluded are exclusions added in the directory
+	Excluded exclusion `json:"excluded" yaml:"excluded" toml:"excluded"`
+	// Patterns are break patterns by file extension in the directory
+	Patterns map[string]string `json:"patterns" yaml:"patterns" toml:"patterns"`
+	// excludedRegexes are Excluded.Regex, compiled
+	excludedRegexes []*regexp.Regexp
+	// patterns are Patterns, compiled
+	patterns map[string]*regexp.Regexp
+}
+
 type config struct {
-	Excluded struct {
-		Path []string `json:"path"`
-	} `json:"excluded"`
+	Excluded exclusion `json:"excluded" yaml:"excluded" toml:"excluded"`
+	// DisableDefaultExclusions analyses vendor, node_modules… as well
+	DisableDefaultExclusions bool `json:"disableDefaultExclusions" yaml:"disableDefaultExclusions" toml:"disableDefaultExclusions"`
+	// IncludeTests analyses test files (`_test.go`) as well
+	IncludeTests bool `json:"includeTests" yaml:"includeTests" toml:"includeTests"`
+	// Diff tunes git diff invocations
+	Diff struct {
+		// Algorithm is the diff algorithm (myers, minimal, patience, histogram)
+		Algorithm string `json:"algorithm" yaml:"algorithm" toml:"algorithm"`
+		// RenameThreshold is the similarity percentage for a file to be seen as renamed (git default if 0)
+		RenameThreshold int `json:"renameThreshold" yaml:"renameThreshold" toml:"renameThreshold"`
+	} `json:"diff" yaml:"diff" toml:"diff"`
+	// FetchRemotes fetches remote branches (`origin/main`) given as points
+	// but missing locally, as in shallow clones
+	FetchRemotes bool `json:"fetchRemotes" yaml:"fetchRemotes" toml:"fetchRemotes"`
+	// Cache is the directory keeping git outputs between analyses of the same
+	// commits, relative to the analysed path, none if empty
+	Cache string `json:"cache" yaml:"cache" toml:"cache"`
+	// Workers is the number of files processed concurrently (CPU count by default)
+	Workers int `json:"workers" yaml:"workers" toml:"workers"`
+	// Languages are the file extensions to analyse, all supported ones if empty
+	Languages []string `json:"languages" yaml:"languages" toml:"languages"`
+	// Patterns are break patterns by file extension, overriding built-in ones
+	Patterns map[string]string `json:"patterns" yaml:"patterns" toml:"patterns"`
+	// MinSeverity is the severity from which breaks are reported (soft, hard),
+	// all of them by default
+	MinSeverity string `json:"minSeverity" yaml:"minSeverity" toml:"minSeverity"`
+	// RequireDeprecation reports methods removed without being deprecated
+	// first in the starting point apart
+	RequireDeprecation bool `json:"requireDeprecation" yaml:"requireDeprecation" toml:"requireDeprecation"`
+	// Aliases are type aliases, names mapped to the type they stand for
+	// (`MyInt: int`), both being equivalent in signatures
+	Aliases map[string]string `json:"aliases" yaml:"aliases" toml:"aliases"`
+	// FailOn are explanations, or their message IDs, failing the analysis
+	// instead of hard breaks
+	FailOn []string `json:"failOn" yaml:"failOn" toml:"failOn"`
+	// Locale is the locale of explanations (en, fr), english by default
+	Locale string `json:"locale" yaml:"locale" toml:"locale"`
+	// Messages are explanations by message ID, overriding the locale ones
+	Messages map[string]string `json:"messages" yaml:"messages" toml:"messages"`
+	// Overrides are settings by directory (path prefix), the most specific
+	// one applying to a file
+	Overrides map[string]*override `json:"overrides" yaml:"overrides" toml:"overrides"`
+	// excludedRegexes are Excluded.Regex, compiled
+	excludedRegexes []*regexp.Regexp
+	// patterns are Patterns, compiled
+	patterns map[string]*regexp.Regexp
+	// minSeverity is MinSeverity, parsed
+	minSeverity Severity
+	// aliases are Aliases, compiled
+	aliases *aliases
 }
 
-// loadConfiguration returns a config struct, loaded from parameters
+// loadConfiguration returns a config struct, loaded from parameters, or nil
+// if there's no config file.
 // It doesn't check workingPath validity, as it's already done higher.
-func loadConfiguration(workingPath string, configFilename string) *config {
+func loadConfiguration(workingPath string, configFilename string) (*config, error) {
 	var conf config
-	if !strings.HasSuffix(workingPath, "/") {
-		workingPath = workingPath + "/"
+	configFilepath, found := discoverConfiguration(workingPath, configFilename)
+	if !found {
+		return nil, nil
 	}
-	configFilepath := workingPath + configFilename
 	configFile, err := os.Open(configFilepath)
-	defer configFile.Close()
 	if err != nil {
+		return nil, fmt.Errorf("Config file %s can't be read : %s", configFilepath, err)
+	}
+	defer configFile.Close()
+	if errDecode := decodeConfiguration(configFile, filepath.Ext(configFilepath), &conf); errDecode != nil {
+		return nil, fmt.Errorf("Invalid config file %s : %s", configFilepath, errDecode)
+	}
+	if conf.excludedRegexes, err = compiledRegexes(conf.Excluded.Regex); err != nil {
+		return nil, err
+	}
+	if "" != conf.MinSeverity {
+		if conf.minSeverity, err = parseSeverity(conf.MinSeverity); err != nil {
+			return nil, err
+		}
+	}
+	if _, known := catalogs[conf.Locale]; "" != conf.Locale && !known {
+		return nil, fmt.Errorf("Unknown locale %s", conf.Locale)
+	}
+	switch conf.Diff.Algorithm {
+	case "", "myers", "minimal", "patience", "histogram":
+	default:
+		return nil, fmt.Errorf("Invalid diff algorithm %s", conf.Diff.Algorithm)
+	}
+	if conf.Diff.RenameThreshold < 0 || conf.Diff.RenameThreshold > 100 {
+		return nil, fmt.Errorf("Invalid rename threshold %d, expecting a percentage", conf.Diff.RenameThreshold)
+	}
+	if conf.patterns, err = compiledPatterns(conf.Patterns); err != nil {
+		return nil, err
+	}
+	if conf.aliases, err = newAliases(conf.Aliases); err != nil {
+		return nil, err
+	}
+	for directory, o := range conf.Overrides {
+		if o == nil {
+			return nil, fmt.Errorf("Empty override for %s", directory)
+		}
+		if o.excludedRegexes, err = compiledRegexes(o.Excluded.Regex); err != nil {
+			return nil, err
+		}
+		if o.patterns, err = compiledPatterns(o.Patterns); err != nil {
+			return nil, err
+		}
+	}
+	return &conf, nil
+}
+
+// compiledRegexes compiles excluded regexes
+func compiledRegexes(exprs []string) ([]*regexp.Regexp, error) {
+	regexes := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		r, errRegex := regexp.Compile(expr)
+		if errRegex != nil {
+			return nil, fmt.Errorf("Invalid excluded regex %s : %s", expr, errRegex)
+		}
+		regexes = append(regexes, r)
+	}
+
+	return regexes, nil
+}
+
+// compiledPatterns compiles break patterns, by file extension
+func compiledPatterns(exprs map[string]string) (map[string]*regexp.Regexp, error) {
+	patterns := make(map[string]*regexp.Regexp, len(exprs))
+	for extension, expr := range exprs {
+		r, errRegex := regexp.Compile(expr)
+		if errRegex != nil {
+			return nil, fmt.Errorf("Invalid pattern for %s : %s", extension, errRegex)
+		}
+		patterns[extension] = r
+	}
+
+	return patterns, nil
+}
+
+// decodeConfiguration decodes a config file according to its extension,
+// rejecting unknown fields whatever the format
+func decodeConfiguration(r io.Reader, extension string, conf *config) error {
+	switch strings.ToLower(extension) {
+	case ".json":
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		return decoder.Decode(conf)
+	case ".yml", ".yaml":
+		decoder := yaml.NewDecoder(r)
+		decoder.KnownFields(true)
+		if err := decoder.Decode(conf); err != nil && err != io.EOF {
+			return err
+		}
 		return nil
+	case ".toml":
+		metadata, err := toml.NewDecoder(r).Decode(conf)
+		if err != nil {
+			return err
+		}
+		if undecoded := metadata.Undecoded(); 0 != len(undecoded) {
+			return fmt.Errorf("unknown field %s", undecoded[0])
+		}
+		return nil
+	}
+
+	return fmt.Errorf("Unsupported format %s, expecting .json, .yml, .yaml or .toml", extension)
+}
+
+// allows tells if a type of file is to be analysed, according to Languages
+func (c *config) allows(typeFile string) bool {
+	if c == nil || 0 == len(c.Languages) {
+		return true
+	}
+	for _, language := range c.Languages {
+		if strings.EqualFold(language, typeFile) {
+			return true
+		}
 	}
-	jsonParser := json.NewDecoder(configFile)
-	jsonParser.Decode(&conf)
-	return &conf
+
+	return false
+}
+
+// includesTests tells if test files are to be analysed
+func (c *config) includesTests() bool {
+	return c != nil && c.IncludeTests
+}
+
+// typeAliases returns type aliases declared, nil if none
+func (c *config) typeAliases() *aliases {
+	if c == nil {
+		return nil
+	}
+
+	return c.aliases
+}
+
+// fetchesRemotes tells if remote branches missing locally are to be fetched
+func (c *config) fetchesRemotes() bool {
+	return c != nil && c.FetchRemotes
+}
+
+// requiresDeprecation tells if removed methods must have been deprecated first
+func (c *config) requiresDeprecation() bool {
+	return c != nil && c.RequireDeprecation
+}
+
+// reports tells if a break of some severity is to be reported, according to
+// MinSeverity
+func (c *config) reports(severity Severity) bool {
+	return c == nil || severity >= c.minSeverity
+}
+
+// pattern returns the break pattern supplied for a type of file, nil if none.
+// The override of the file directory comes first
+func (c *config) pattern(name string, typeFile string) *regexp.Regexp {
+	if c == nil || "" == typeFile {
+		return nil
+	}
+	if o := c.override(name); o != nil && o.patterns[typeFile] != nil {
+		return o.patterns[typeFile]
+	}
+
+	return c.patterns[typeFile]
+}
+
+// override returns the override of the most specific directory holding a
+// file, nil if none
+func (c *config) override(name string) *override {
+	if c == nil {
+		return nil
+	}
+	var closest *override
+	closestLength := -1
+	for directory, o := range c.Overrides {
+		directory = strings.TrimSuffix(directory, "/")
+		if strings.HasPrefix(name, directory+"/") && len(directory) > closestLength {
+			closest, closestLength = o, len(directory)
+		}
+	}
+
+	return closest
+}
+
+// excludes checks if a path satisfies an exclusion criteria of the override
+func (o *override) excludes(name string) bool {
+	for _, g := range o.Excluded.Glob {
+		if matchGlob(g, name) {
+			return true
+		}
+	}
+	for _, e := range o.Excluded.Path {
+		if strings.HasPrefix(name, e) {
+			return true
+		}
+	}
+	for _, r := range o.excludedRegexes {
+		if r.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// discoverConfiguration looks for the config file from workingPath up to the
+// root of the repository, the closest one winning
+func discoverConfiguration(workingPath string, configFilename string) (string, bool) {
+	dir, err := filepath.Abs(workingPath)
+	if err != nil {
+		return "", false
+	}
+	for {
+		candidate := filepath.Join(dir, configFilename)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, true
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", false
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// aliases are type aliases, resolved in signatures before comparing them
+type aliases struct {
+	types   map[string]string
+	pattern *regexp.Regexp
+}
+
+// newAliases compiles type aliases, nil if none
+func newAliases(types map[string]string) (*aliases, error) {
+	if 0 == len(types) {
+		return nil, nil
+	}
+	names := make([]string, 0, len(types))
+	for name, aliased := range types {
+		if "" == strings.TrimSpace(name) || "" == strings.TrimSpace(aliased) {
+			return nil, fmt.Errorf("Invalid alias %s = %s", name, aliased)
+		}
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	// Longest names first, for a qualified one to win over its suffix
+	sort.Slice(names, func(i, j int) bool {
+		return len(names[i]) > len(names[j])
+	})
+
+	return &aliases{
+		types:   types,
+		pattern: regexp.MustCompile(`\b(` + strings.Join(names, "|") + `)\b`),
+	}, nil
+}
+
+// resolved replaces aliases of a declaration by the types they stand for.
+// Only types are resolved: types of parameters, return types and whatever
+// surrounds the parameter list, names aside
+func (a *aliases) resolved(declaration string, name string, typeFile string) string {
+	if a == nil || "" == declaration {
+		return declaration
+	}
+	offset := 0
+	if "go" == typeFile {
+		offset = len(goReceiverPattern.FindString(declaration))
+	}
+	start := strings.Index(declaration[offset:], "(")
+	if start == -1 {
+		// Field, constant or type, no parameter
+		return a.replaced(declaration, name)
+	}
+	start += offset
+	list, rest := splitSignature(declaration[start:])
+	params := parameters(declaration[start:])
+	unnamed := "go" == typeFile && !goNamedParameters(params)
+	for i, parameter := range params {
+		parameterName, parameterType := parameterParts(parameter, typeFile)
+		if unnamed {
+			parameterName, parameterType = "", parameter
+		}
+		params[i] = a.parameterResolved(parameter, parameterName, parameterType, typeFile)
+	}
+	closing := ""
+	if strings.HasPrefix(declaration[start+1+len(list):], ")") {
+		closing = ")"
+	}
+
+	// The receiver of a go method being a type
+	return a.replaced(declaration[:offset], "") + a.replaced(declaration[offset:start], name) + "(" + strings.Join(params, ", ") + closing + a.replaced(rest, "")
+}
+
+// parameterResolved replaces aliases of the type of a parameter
+func (a *aliases) parameterResolved(parameter string, name string, parameterType string, typeFile string) string {
+	if "" == parameterType {
+		return parameter
+	}
+	from := 0
+	if !isTypeFirst(typeFile) && strings.HasPrefix(parameter, name) {
+		// name Type
+		from = len(name)
+	}
+	position := strings.Index(parameter[from:], parameterType)
+	if position == -1 {
+		// Type normalized, as c++ ones
+		return a.replaced(parameter, name)
+	}
+	position += from
+
+	return parameter[:position] + a.replaced(parameterType, "") + parameter[position+len(parameterType):]
+}
+
+// replaced replaces aliases of a text by the types they stand for, but the
+// given name
+func (a *aliases) replaced(text string, name string) string {
+	return a.pattern.ReplaceAllStringFunc(text, func(alias string) string {
+		if alias == name {
+			return alias
+		}
+		return a.types[alias]
+	})
 }
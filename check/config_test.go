@@ -0,0 +1,297 @@
+package check
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// configured returns a break using a config file of the given content
+func configured(t *testing.T, filename string, content string) *Break {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, filename), content)
+	conf, err := loadConfiguration(dir, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Break{config: conf}
+}
+
+func TestExcludedByGlob(t *testing.T) {
+	b := configured(t, "check-break.json", `{"includeTests": true, "excluded": {"glob": ["*_test.go"]}}`)
+
+	if !b.isExcluded("pkg/foo_test.go") {
+		t.Error("Expected test file to be excluded")
+	}
+	if b.isExcluded("pkg/foo.go") {
+		t.Error("Expected source file not to be excluded")
+	}
+}
+
+func TestExcludedByRegex(t *testing.T) {
+	b := configured(t, "check-break.json", `{"excluded": {"regex": [".*\\.generated\\.(go|ts)$"]}}`)
+
+	for _, name := range []string{"api/foo.generated.go", "web/foo.generated.ts"} {
+		if !b.isExcluded(name) {
+			t.Errorf("Expected %s to be excluded", name)
+		}
+	}
+	if b.isExcluded("api/foo.go") {
+		t.Error("Expected source file not to be excluded")
+	}
+}
+
+func TestDefaultExclusions(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{
+			"check-break.json":      `{"disableDefaultExclusions": true}`,
+			"node_modules/dep/a.js": "export function foo(a) {\n}\n",
+			"vendor/dep/a.go":       "package dep\n\nfunc Foo(a int) {\n}\n",
+		},
+		map[string]string{
+			"node_modules/dep/a.js": "export function foo() {\n}\n",
+			"vendor/dep/a.go":       "package dep\n\nfunc Foo() {\n}\n",
+		})
+
+	if results := analyzed(t, dir, "none.json"); 0 != len(results) {
+		t.Errorf("Expected vendored files to be excluded, got %v", results)
+	}
+	results := analyzed(t, dir, "check-break.json")
+	for _, name := range []string{"node_modules/dep/a.js", "vendor/dep/a.go"} {
+		assertExplanations(t, breaksOf(results, name), "Deletion of parameter")
+	}
+}
+
+func TestGoTestFiles(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{
+			"check-break.json": `{"includeTests": true}`,
+			"foo_test.go":      "package foo\n\nfunc Helper(a int) {\n}\n",
+		},
+		map[string]string{"foo_test.go": "package foo\n\nfunc Helper() {\n}\n"})
+
+	if results := analyzed(t, dir, "none.json"); 0 != len(results) {
+		t.Errorf("Expected test files to be ignored, got %v", results)
+	}
+	assertExplanations(t, breaksOf(analyzed(t, dir, "check-break.json"), "foo_test.go"), "Deletion of parameter")
+}
+
+func TestLanguagesAllowlist(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{
+			"check-break.json": `{"languages": ["go"]}`,
+			"foo.go":           "package foo\n\nfunc Foo(a int) {\n}\n",
+			"foo.py":           "def foo(a):\n    pass\n",
+		},
+		map[string]string{
+			"foo.go": "package foo\n\nfunc Foo() {\n}\n",
+			"foo.py": "def foo():\n    pass\n",
+		})
+	b, err := Init(dir, "start", "HEAD", "check-break.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := b.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if 1 != len(report.Supported) || "foo.go" != report.Supported[0].filename {
+		t.Errorf("Expected only foo.go to be analysed, got %v", report.Supported)
+	}
+	if 1 != len(report.Ignored) || "foo.py" != report.Ignored[0].name {
+		t.Errorf("Expected foo.py to be ignored, got %v", report.Ignored)
+	}
+}
+
+func TestConfigFormats(t *testing.T) {
+	tests := []struct {
+		filename string
+		content  string
+	}{
+		{"check-break.json", `{"excluded": {"glob": ["gen/**"], "regex": [".*\\.pb\\.go$"]}}`},
+		{"check-break.yml", "excluded:\n  glob: [\"gen/**\"]\n  regex: ['.*\\.pb\\.go$']\n"},
+		{"check-break.yaml", "excluded:\n  glob:\n    - gen/**\n  regex:\n    - '.*\\.pb\\.go$'\n"},
+		{"check-break.toml", "[excluded]\nglob = [\"gen/**\"]\nregex = ['.*\\.pb\\.go$']\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			b := configured(t, tt.filename, tt.content)
+
+			for _, name := range []string{"gen/foo.go", "api/foo.pb.go"} {
+				if !b.isExcluded(name) {
+					t.Errorf("Expected %s to be excluded", name)
+				}
+			}
+			if b.isExcluded("api/foo.go") {
+				t.Error("Expected source file not to be excluded")
+			}
+		})
+	}
+}
+
+func TestUnsupportedConfigFormat(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "check-break.ini"), "[excluded]\n")
+
+	_, err := loadConfiguration(dir, "check-break.ini")
+	if err == nil || !strings.Contains(err.Error(), "Unsupported format .ini") {
+		t.Errorf("Expected an unsupported format, got %v", err)
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	signature := func(params string) string {
+		return "package gen\n\nfunc Foo(" + params + ") {\n}\n"
+	}
+	dir := newRepo(t,
+		map[string]string{
+			"check-break.json": `{"overrides": {
+				"api": {"excluded": {"glob": ["api/gen/**"]}},
+				"tools/": {"patterns": {"mylang": "^proc [a-z]+\\("}}
+			}}`,
+			"api/gen/foo.go":     signature("a int"),
+			"web/gen/foo.go":     signature("a int"),
+			"tools/lib.mylang":   "proc foo(a)\nproc bar(a)\n",
+			"scripts/lib.mylang": "proc foo(a)\nproc bar(a)\n",
+		},
+		map[string]string{
+			"api/gen/foo.go":     signature(""),
+			"web/gen/foo.go":     signature(""),
+			"tools/lib.mylang":   "proc foo(a)\n",
+			"scripts/lib.mylang": "proc foo(a)\n",
+		})
+
+	results := analyzed(t, dir, "check-break.json")
+	tests := []struct {
+		filename string
+		expected []string
+	}{
+		{"api/gen/foo.go", nil},
+		{"web/gen/foo.go", []string{"Deletion of parameter"}},
+		{"tools/lib.mylang", []string{"Deletion of method"}},
+		{"scripts/lib.mylang", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			assertExplanations(t, breaksOf(results, tt.filename), tt.expected...)
+		})
+	}
+}
+
+func TestMostSpecificOverride(t *testing.T) {
+	b := configured(t, "check-break.json", `{"overrides": {
+		"api": {"excluded": {"glob": ["**/*.pb.go"]}},
+		"api/internal": {"excluded": {"glob": ["**/mock_*.go"]}}
+	}}`)
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"api/foo.pb.go", true},
+		{"api/mock_foo.go", false},
+		{"api/internal/mock_foo.go", true},
+		{"api/internal/foo.pb.go", false},
+		{"apiv2/foo.pb.go", false},
+	}
+	for _, tt := range tests {
+		if excluded := b.isExcluded(tt.name); tt.expected != excluded {
+			t.Errorf("Expected exclusion of %s to be %t, got %t", tt.name, tt.expected, excluded)
+		}
+	}
+}
+
+func TestInvalidExclusionRegex(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "check-break.json"), `{"excluded": {"regex": ["(unclosed"]}}`)
+
+	if _, err := loadConfiguration(dir, "check-break.json"); err == nil {
+		t.Error("Expected an invalid regex to fail loading")
+	}
+}
+
+func TestConfigDiscoveredUpward(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{".checkbreak.yml": "excluded:\n  path: [generated]\n", "a/b/foo.go": "package b\n"},
+		map[string]string{})
+
+	b, err := Init(filepath.Join(dir, "a", "b"), "start", "HEAD", ".checkbreak.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !b.HasConfiguration() {
+		t.Fatal("Expected the config of the repository root to be found")
+	}
+	if !b.isExcluded("generated/foo.go") {
+		t.Error("Expected the config of the repository root to apply")
+	}
+}
+
+func TestConfigNotFound(t *testing.T) {
+	dir := newRepo(t, map[string]string{"foo.go": "package foo\n"}, map[string]string{})
+
+	b, err := Init(dir, "start", "HEAD", ".checkbreak.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.HasConfiguration() {
+		t.Error("Expected no config")
+	}
+}
+
+func TestMalformedConfig(t *testing.T) {
+	tests := []struct {
+		filename string
+		content  string
+		expected string
+	}{
+		{"check-break.json", `{"excluded": {"path": [`, "check-break.json"},
+		{"check-break.json", `{"exclude": {"path": ["vendor"]}}`, "exclude"},
+		{"check-break.yml", "excluded:\n  path: [vendor\n", "check-break.yml"},
+		{"check-break.json", `{"diff": {"algorithm": "fastest"}}`, "Invalid diff algorithm fastest"},
+		{"check-break.json", `{"diff": {"renameThreshold": 150}}`, "Invalid rename threshold 150"},
+	}
+	for _, tt := range tests {
+		dir := newRepo(t, map[string]string{tt.filename: tt.content}, map[string]string{})
+
+		_, err := Init(dir, "start", "HEAD", tt.filename)
+		if err == nil {
+			t.Errorf("Expected %s to be rejected", tt.content)
+		} else if !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Expected %q in the error, got %s", tt.expected, err)
+		}
+	}
+}
+
+func TestCustomPattern(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{
+			"check-break.json": `{"patterns": {"mylang": "^proc [a-z]+\\("}}`,
+			"lib.mylang":       "proc foo(a, b)\nproc bar(a)\n",
+		},
+		map[string]string{"lib.mylang": "proc foo(a, b)\n"})
+
+	results := analyzed(t, dir, "check-break.json")
+	assertExplanations(t, breaksOf(results, "lib.mylang"), "Deletion of method")
+}
+
+func TestInvalidCustomPattern(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "check-break.json"), `{"patterns": {"mylang": "proc ("}}`)
+
+	if _, err := loadConfiguration(dir, "check-break.json"); err == nil {
+		t.Error("Expected an invalid pattern to fail loading")
+	}
+}
+
+func TestInvalidAlias(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "check-break.json"), `{"aliases": {"MyInt": " "}}`)
+
+	if _, err := loadConfiguration(dir, "check-break.json"); err == nil || !strings.HasPrefix(err.Error(), "Invalid alias") {
+		t.Errorf("Expected an invalid alias error, got %v", err)
+	}
+}
@@ -1,31 +1,262 @@
 package check
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
-
-	"github.com/tbruyelle/git"
-	"github.com/tbruyelle/qexec"
 )
 
-func refExists(point string) bool {
-	exists, _ := git.RefExists(point)
-	return exists
+// WorkingTree is the ending point standing for the uncommitted changes of the
+// working tree
+const WorkingTree = "WORKING"
+
+// Index is the ending point standing for the staged changes
+const Index = "INDEX"
+
+// isRef tells if a point is a git reference, not a sentinel
+func isRef(point string) bool {
+	return WorkingTree != point && Index != point
+}
+
+// revisions are the arguments of git diff comparing the two points of b. The
+// ending point is compared to the merge base of both (`start...end`), unless
+// they're compared directly (`start..end`)
+func (b *Break) revisions() []string {
+	switch b.endPoint {
+	case WorkingTree:
+		return []string{b.startPoint}
+	case Index:
+		return []string{"--cached", b.startPoint}
+	}
+	if b.direct {
+		return []string{b.startPoint + ".." + b.endPoint}
+	}
+
+	return []string{b.startPoint + "..." + b.endPoint}
+}
+
+// basePoint is the point the ending point is compared to: the merge base of
+// both points, unless they're compared directly
+func (b *Break) basePoint(ctx context.Context) (string, error) {
+	if b.direct || !isRef(b.endPoint) {
+		return b.startPoint, nil
+	}
+	base, err := b.gitRunner().Run(ctx, "merge-base", b.startPoint, b.endPoint)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(base), nil
+}
+
+// GitRunner runs git commands, returning their output. It allows embedders
+// and tests to sandbox or fake git
+type GitRunner interface {
+	Run(ctx context.Context, args ...string) (string, error)
+}
+
+// execRunner is the default GitRunner, running the git binary in dir
+type execRunner struct {
+	dir string
 }
 
-func diffFileList(startPoint string, endPoint string) ([]string, error) {
-	gitFiles, err := qexec.Run("git", "diff", "--name-status", startPoint+"..."+endPoint)
+// Run runs a git command, killing it as soon as ctx is done. Only its
+// standard output is returned, the standard error explaining failures
+func (r execRunner) Run(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return "", ctxErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s : %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return string(out), nil
+}
+
+// commit resolves a point to its commit, telling if it exists. A point is a
+// commit, a branch, a remote-tracking branch (`origin/main`) or a tag,
+// annotated ones being peeled to the commit they tag
+func (b *Break) commit(point string) (string, bool) {
+	hash, err := b.gitRunner().Run(context.Background(), "rev-parse", "--verify", "--quiet", point+"^{commit}")
+	if err != nil {
+		return "", false
+	}
+
+	return strings.TrimSpace(hash), true
+}
+
+// resolved returns the commit of a point, fetching it first if it's a remote
+// branch missing locally and config allows it
+func (b *Break) resolved(point string) (string, error) {
+	if hash, exists := b.commit(point); exists {
+		return hash, nil
+	}
+	if b.config.fetchesRemotes() {
+		fetched, err := b.fetch(point)
+		if err != nil {
+			return "", err
+		}
+		if hash, exists := b.commit(point); fetched && exists {
+			return hash, nil
+		}
+	}
+
+	return "", &RefNotFoundError{Point: point}
+}
+
+// fetch fetches a remote branch (`origin/main`) into its remote-tracking
+// ref, telling if point names one
+func (b *Break) fetch(point string) (bool, error) {
+	parts := strings.SplitN(point, "/", 2)
+	if len(parts) < 2 || "" == parts[1] {
+		return false, nil
+	}
+	remote, branch := parts[0], parts[1]
+	remotes, err := b.gitRunner().Run(context.Background(), "remote")
+	if err != nil {
+		return false, fmt.Errorf("Remotes can't be listed : %s", err)
+	}
+	for _, known := range strings.Fields(remotes) {
+		if known != remote {
+			continue
+		}
+		refspec := "+refs/heads/" + branch + ":refs/remotes/" + remote + "/" + branch
+		if _, err := b.gitRunner().Run(context.Background(), "fetch", "--quiet", "--no-tags", remote, refspec); err != nil {
+			return true, fmt.Errorf("Fetching %s from %s failed : %s", branch, remote, err)
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// diffFileList lists changed files. Renamed ones, even modified, are paired
+// whatever the diff.renames setting of the user
+func (b *Break) diffFileList(ctx context.Context) ([]string, error) {
+	if b.samePoints {
+		return make([]string, 0), nil
+	}
+	if b.fileList != nil {
+		return b.givenFileList()
+	}
+	args := append(append([]string{"diff", "--name-status"}, b.diffOptions()...), b.revisions()...)
+	args = append(append(args, "--"), b.paths...)
+	gitFiles, err := b.gitRunner().Run(ctx, args...)
 	if err != nil {
 		return make([]string, 0), err
 	}
 	if len(gitFiles) == 0 {
+		if 0 != len(b.paths) {
+			// Untouched paths simply have no break
+			return make([]string, 0), nil
+		}
 		return make([]string, 0), errors.New("No changed file between these two points")
 	}
 	return strings.Split(strings.TrimSpace(gitFiles), "\n"), nil
 }
 
-func diffFile(startPoint string, endPoint string, filename string) ([]string, error) {
-	diff, err := qexec.Run("git", "diff", "-U0", startPoint+"..."+endPoint, filename)
+// ReadFileList reads changed files from r, in `git diff --name-status`
+// format, rather than listing them from the two points. Their changes are
+// still computed between both points
+func (b *Break) ReadFileList(r io.Reader) error {
+	fileList := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if "" == strings.TrimSpace(line) {
+			continue
+		}
+		if !isNameStatusLine(line) {
+			return fmt.Errorf("Invalid name-status line %s, expecting a status and tab-separated paths", line)
+		}
+		fileList = append(fileList, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	b.fileList = fileList
+
+	return nil
+}
+
+// isNameStatusLine tells if line is a status followed by its paths, two of
+// them for a rename or a copy (`R100 old.go new.go`, tab-separated)
+func isNameStatusLine(line string) bool {
+	fields := strings.Split(line, "\t")
+	expected := 2
+	if strings.HasPrefix(fields[0], "R") || strings.HasPrefix(fields[0], "C") {
+		expected = 3
+	}
+	if expected != len(fields) {
+		return false
+	}
+	for _, field := range fields {
+		if "" == field {
+			return false
+		}
+	}
+
+	return true
+}
+
+// givenFileList lists changed files read by ReadFileList, restricted to
+// paths as git would
+func (b *Break) givenFileList() ([]string, error) {
+	if 0 == len(b.paths) {
+		if 0 == len(b.fileList) {
+			return make([]string, 0), errors.New("No changed file between these two points")
+		}
+		return b.fileList, nil
+	}
+	restricted := make([]string, 0)
+	for _, fileLine := range b.fileList {
+		_, name, _, _ := extractDataFile(fileLine)
+		for _, path := range b.paths {
+			if path = strings.TrimSuffix(filepath.ToSlash(path), "/"); name == path || strings.HasPrefix(name, path+"/") {
+				restricted = append(restricted, fileLine)
+				break
+			}
+		}
+	}
+
+	return restricted, nil
+}
+
+// diffOptions are options of git diff shared by all invocations, so that
+// renames are paired the same way
+func (b *Break) diffOptions() []string {
+	renames := "-M"
+	if b.HasConfiguration() && b.config.Diff.RenameThreshold > 0 {
+		renames = fmt.Sprintf("-M%d%%", b.config.Diff.RenameThreshold)
+	}
+	options := []string{renames}
+	if b.HasConfiguration() && "" != b.config.Diff.Algorithm {
+		options = append(options, "--diff-algorithm="+b.config.Diff.Algorithm)
+	}
+
+	return options
+}
+
+// fullContext keeps the whole file in a diff, so that changes can be located
+// in their declaration block
+const fullContext = "-U1000000"
+
+func (b *Break) diffFile(ctx context.Context, filenames ...string) ([]string, error) {
+	args := append(append([]string{"diff", fullContext}, b.diffOptions()...), b.revisions()...)
+	args = append(append(args, "--"), filenames...)
+	diff, err := b.gitRunner().Run(ctx, args...)
 	if err != nil {
 		return make([]string, 0), err
 	}
@@ -33,8 +264,22 @@ func diffFile(startPoint string, endPoint string, filename string) ([]string, er
 	return strings.Split(diff, "\n"), nil
 }
 
-func showFile(startPoint string, filename string) ([]string, error) {
-	diff, err := qexec.Run("git", "show", startPoint+":"+filename)
+func (b *Break) showFile(ctx context.Context, startPoint string, filename string) ([]string, error) {
+	if WorkingTree == startPoint {
+		root, err := b.gitRunner().Run(ctx, "rev-parse", "--show-toplevel")
+		if err != nil {
+			return make([]string, 0), err
+		}
+		content, err := os.ReadFile(filepath.Join(strings.TrimSpace(root), filename))
+		if err != nil {
+			return make([]string, 0), err
+		}
+		return strings.Split(string(content), "\n"), nil
+	}
+	if Index == startPoint {
+		startPoint = ""
+	}
+	diff, err := b.gitRunner().Run(ctx, "show", startPoint+":"+filename)
 	if err != nil {
 		return make([]string, 0), err
 	}
@@ -0,0 +1,613 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractDataFileRename(t *testing.T) {
+	status, name, previousName, typeFile := extractDataFile("R100\told/foo.go\tnew/foo.go")
+
+	if "R" != status || "new/foo.go" != name || "old/foo.go" != previousName || "go" != typeFile {
+		t.Errorf("Unexpected rename parsing : %s %s %s %s", status, name, previousName, typeFile)
+	}
+}
+
+func TestExtractDataFileWarning(t *testing.T) {
+	status, name, _, _ := extractDataFile("warning: exhaustive rename detection was skipped due to too many files.")
+
+	if "" != status || "" != name {
+		t.Errorf("Expected a warning not to be a file, got %s %s", status, name)
+	}
+}
+
+func TestPureRenameHasNoBreak(t *testing.T) {
+	content := "package foo\n\nfunc Foo(a int) {\n}\n\nfunc Bar(b string) {\n}\n"
+	dir := newRepo(t,
+		map[string]string{"old/foo.go": content},
+		map[string]string{"old/foo.go": "", "new/foo.go": content})
+
+	if results := analyzed(t, dir, "none.json"); 0 != len(results) {
+		t.Errorf("Expected no break, got %v", results)
+	}
+}
+
+func TestRenamedAndModified(t *testing.T) {
+	content := "package foo\n\nfunc Foo(a int) {\n}\n\nfunc Bar(b string) {\n}\n\nfunc Baz(c bool) {\n}\n"
+	dir := newRepo(t,
+		map[string]string{"old/foo.go": content},
+		map[string]string{"old/foo.go": "", "new/foo.go": strings.Replace(content, "Bar(b string)", "Bar()", 1)})
+
+	results := analyzed(t, dir, "none.json")
+	if 1 != len(results) || "old/foo.go" != results[0].PreviousFilename {
+		t.Fatalf("Expected a renamed file, got %v", results)
+	}
+	assertExplanations(t, breaksOf(results, "new/foo.go"), "Deletion of parameter")
+}
+
+func TestMergeBaseComparison(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{"foo.go": "package foo\n\nfunc Foo(a int, b int) {\n}\n"},
+		map[string]string{})
+	git(t, dir, "checkout", "-q", "-b", "feature")
+	writeFiles(t, dir, map[string]string{"foo.go": "package foo\n\nfunc Foo(a int) {\n}\n"})
+	git(t, dir, "commit", "-q", "-am", "feature")
+	// Main moves on after the branch diverged
+	git(t, dir, "checkout", "-q", "start")
+	git(t, dir, "checkout", "-q", "-b", "main")
+	writeFiles(t, dir, map[string]string{"foo.go": "package foo\n\nfunc Foo(a int, b int) {\n}\n\nfunc Bar() {\n}\n"})
+	git(t, dir, "commit", "-q", "-am", "main")
+
+	tests := []struct {
+		name     string
+		direct   bool
+		expected []string
+	}{
+		{"merge base", false, []string{"Deletion of parameter"}},
+		{"direct", true, []string{"Deletion of method", "Deletion of parameter"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Init(dir, "main", "feature", "none.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.direct {
+				b.CompareDirectly()
+			}
+			results, err := b.Analyze()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertExplanations(t, breaksOf(results, "foo.go"), tt.expected...)
+		})
+	}
+}
+
+func TestTagsAndRemoteRefs(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{"foo.go": "package foo\n\nfunc Foo(a int) {\n}\n\nfunc Bar() {\n}\n"},
+		map[string]string{"foo.go": "package foo\n\nfunc Foo(a int) {\n}\n"})
+	git(t, dir, "tag", "-a", "v1.0.0", "-m", "v1.0.0", "start")
+	git(t, dir, "tag", "-a", "v2.0.0", "-m", "v2.0.0", "HEAD")
+	git(t, dir, "update-ref", "refs/remotes/origin/main", "HEAD")
+
+	tests := []struct {
+		name  string
+		start string
+		end   string
+	}{
+		{"annotated tags", "v1.0.0", "v2.0.0"},
+		{"remote-tracking branch", "v1.0.0", "origin/main"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Init(dir, tt.start, tt.end, "none.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := b.Analyze()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertExplanations(t, breaksOf(results, "foo.go"), "Deletion of method")
+		})
+	}
+}
+
+func TestUnknownRef(t *testing.T) {
+	dir := newRepo(t, map[string]string{"foo.go": "package foo\n"}, map[string]string{})
+
+	_, err := Init(dir, "v9.9.9", "HEAD", "none.json")
+	var notFound *RefNotFoundError
+	if !errors.As(err, &notFound) || "v9.9.9" != notFound.Point {
+		t.Errorf("Expected v9.9.9 not to be found, got %v", err)
+	}
+}
+
+func TestTransientFiles(t *testing.T) {
+	dir := newRepo(t, map[string]string{"foo.go": "package foo\n\nfunc Foo(a int) {\n}\n"}, map[string]string{})
+	writeFiles(t, dir, map[string]string{
+		"tmp.go": "package foo\n\nfunc Tmp(a int) {\n}\n",
+		"new.go": "package foo\n\nfunc New(a int) {\n}\n",
+	})
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "added")
+	// Removed, or changed, before the ending point
+	writeFiles(t, dir, map[string]string{"tmp.go": "", "new.go": "package foo\n\nfunc New() {\n}\n"})
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "removed")
+
+	b, err := Init(dir, "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := b.ListFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if 0 != len(list.Analysable) || 0 != len(list.Ignored) {
+		t.Errorf("Expected no file to analyse, got %v", list)
+	}
+	if results := analyzed(t, dir, "none.json"); 0 != len(results) {
+		t.Errorf("Expected no break, got %v", results)
+	}
+}
+
+func TestSamePoints(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{"foo.go": "package foo\n\nfunc Foo(a int) {\n}\n"},
+		map[string]string{"foo.go": "package foo\n\nfunc Foo() {\n}\n"})
+	git(t, dir, "tag", "-a", "v1.0.0", "-m", "v1.0.0", "HEAD")
+	git(t, dir, "tag", "release", "HEAD")
+
+	tests := []struct {
+		name  string
+		start string
+		end   string
+	}{
+		{"same ref", "HEAD", "HEAD"},
+		{"same commit", "release", "v1.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var commands []string
+			runner := recordingRunner{runner: execRunner{dir: dir}, commands: &commands}
+			b, err := InitWithRunner(dir, tt.start, tt.end, "none.json", runner)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !b.SamePoints() {
+				t.Error("Expected both points to be the same")
+			}
+			results, err := b.Analyze()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if 0 != len(results) {
+				t.Errorf("Expected no result, got %v", results)
+			}
+			for _, command := range commands {
+				if strings.HasPrefix(command, "diff ") {
+					t.Errorf("Expected no diff, got %q", command)
+				}
+			}
+		})
+	}
+}
+
+func TestWorkingTreeComparison(t *testing.T) {
+	content := "package foo\n\nfunc Foo(a int) {\n}\n"
+	dir := newRepo(t, map[string]string{"foo.go": content}, map[string]string{})
+	writeFiles(t, dir, map[string]string{"foo.go": "package foo\n\nfunc Foo() {\n}\n"})
+
+	b, err := Init(dir, "HEAD", WorkingTree, "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := b.Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertExplanations(t, breaksOf(results, "foo.go"), "Deletion of parameter")
+}
+
+func TestStagedComparison(t *testing.T) {
+	content := "package foo\n\nfunc Foo(a int) {\n}\n\nfunc Bar() {\n}\n"
+	dir := newRepo(t, map[string]string{"foo.go": content}, map[string]string{})
+	writeFiles(t, dir, map[string]string{"foo.go": "package foo\n\nfunc Foo(a int) {\n}\n"})
+	git(t, dir, "add", "foo.go")
+	// Not staged, thus not analysed
+	writeFiles(t, dir, map[string]string{"foo.go": "package foo\n\nfunc Foo() {\n}\n"})
+
+	b, err := Init(dir, "HEAD", Index, "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := b.Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertExplanations(t, breaksOf(results, "foo.go"), "Deletion of method")
+}
+
+// fakeRunner is a GitRunner answering canned outputs, without any repository
+type fakeRunner struct {
+	nameStatus string
+	diff       string
+	err        error
+	// commands records git commands run, if not nil
+	commands *[]string
+}
+
+// Run answers the canned output of a git command
+func (r fakeRunner) Run(ctx context.Context, args ...string) (string, error) {
+	if r.commands != nil {
+		*r.commands = append(*r.commands, strings.Join(args, " "))
+	}
+	switch {
+	case "rev-parse" == args[0]:
+		// Each point is its own commit
+		return args[len(args)-1] + "\n", nil
+	case "merge-base" == args[0]:
+		return args[1] + "\n", nil
+	case r.err != nil:
+		return "", r.err
+	case "diff" == args[0] && "--name-status" == args[1]:
+		return r.nameStatus, nil
+	case "diff" == args[0]:
+		return r.diff, nil
+	}
+
+	return "", fmt.Errorf("Unexpected git command %v", args)
+}
+
+// recordingRunner runs git commands, recording them
+type recordingRunner struct {
+	runner   GitRunner
+	commands *[]string
+}
+
+// Run records a git command, then runs it
+func (r recordingRunner) Run(ctx context.Context, args ...string) (string, error) {
+	*r.commands = append(*r.commands, strings.Join(args, " "))
+
+	return r.runner.Run(ctx, args...)
+}
+
+func TestFakeRunner(t *testing.T) {
+	runner := fakeRunner{
+		nameStatus: "M\tfoo.go\n",
+		diff: "diff --git a/foo.go b/foo.go\n" +
+			"index 0123456..89abcde 100644\n" +
+			"--- a/foo.go\n" +
+			"+++ b/foo.go\n" +
+			"@@ -1,7 +1,4 @@\n" +
+			" package foo\n" +
+			" \n" +
+			"-func Foo(a int, b int) {\n" +
+			"+func Foo(a int) {\n" +
+			" }\n" +
+			"-\n" +
+			"-func Bar() {\n" +
+			"-}\n",
+	}
+	b, err := InitWithRunner(t.TempDir(), "start", "HEAD", "none.json", runner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := b.Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertExplanations(t, breaksOf(results, "foo.go"), "Deletion of method", "Deletion of parameter")
+}
+
+func TestNameStatusWarning(t *testing.T) {
+	runner := fakeRunner{
+		nameStatus: "warning: exhaustive rename detection was skipped due to too many files.\nM\tfoo.go\n",
+		diff: "diff --git a/foo.go b/foo.go\n" +
+			"--- a/foo.go\n" +
+			"+++ b/foo.go\n" +
+			"@@ -1,5 +1,2 @@\n" +
+			" package foo\n" +
+			" \n" +
+			"-func Bar() {\n" +
+			"-}\n",
+	}
+	b, err := InitWithRunner(t.TempDir(), "start", "HEAD", "none.json", runner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := b.Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if 1 != len(results) {
+		t.Fatalf("Expected results of foo.go only, got %v", results)
+	}
+	assertExplanations(t, breaksOf(results, "foo.go"), "Deletion of method")
+}
+
+func TestFakeRunnerFailure(t *testing.T) {
+	runner := fakeRunner{err: errors.New("Repository is sandboxed")}
+	b, err := InitWithRunner(t.TempDir(), "start", "HEAD", "none.json", runner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Analyze(); !errors.Is(err, runner.err) {
+		t.Errorf("Expected %q, got %v", runner.err, err)
+	}
+}
+
+func TestExecRunnerStreams(t *testing.T) {
+	runner := execRunner{dir: t.TempDir()}
+
+	out, err := runner.Run(context.Background(), "-c", "alias.both=!echo out; echo warning >&2", "both")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if "out\n" != out {
+		t.Errorf("Expected standard output only, got %q", out)
+	}
+	_, err = runner.Run(context.Background(), "-c", "alias.failing=!echo out; echo failure >&2; false", "failing")
+	if err == nil || !strings.HasSuffix(err.Error(), " : failure") {
+		t.Errorf("Expected the standard error in the error, got %v", err)
+	}
+}
+
+func TestTypefile(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"foo.go", "go"},
+		{"pkg/Foo.JAVA", "java"},
+		{"types/schema.d.ts", "ts"},
+		{"web/Foo.test.js", "js"},
+		{"archive.tar.gz", "gz"},
+		{"bin/go", ""},
+		{"Makefile", ""},
+		{".bashrc", ""},
+		{"conf/.eslintrc.js", "js"},
+		{"v1.2/foo", ""},
+		{"foo.", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := typefile(tt.path); tt.expected != got {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDiffOptionsForwarded(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected []string
+	}{
+		{"defaults", `{}`, []string{
+			"diff --name-status -M start...HEAD --",
+			"diff -U1000000 -M start...HEAD -- foo.go",
+		}},
+		{"configured", `{"diff": {"algorithm": "histogram", "renameThreshold": 80}}`, []string{
+			"diff --name-status -M80% --diff-algorithm=histogram start...HEAD --",
+			"diff -U1000000 -M80% --diff-algorithm=histogram start...HEAD -- foo.go",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "check-break.json"), tt.config)
+			var commands []string
+			runner := fakeRunner{nameStatus: "M\tfoo.go\n", commands: &commands}
+			b, err := InitWithRunner(dir, "start", "HEAD", "check-break.json", runner)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := b.Analyze(); err != nil {
+				t.Fatal(err)
+			}
+
+			diffs := make([]string, 0)
+			for _, command := range commands {
+				if strings.HasPrefix(command, "diff ") {
+					diffs = append(diffs, command)
+				}
+			}
+			if !reflect.DeepEqual(tt.expected, diffs) {
+				t.Errorf("Expected %q, got %q", tt.expected, diffs)
+			}
+		})
+	}
+}
+
+func TestBinaryDiff(t *testing.T) {
+	runner := fakeRunner{
+		nameStatus: "M\tassets/blob.go\n",
+		diff: "diff --git a/assets/blob.go b/assets/blob.go\n" +
+			"index 0123456..89abcde 100644\n" +
+			"Binary files a/assets/blob.go and b/assets/blob.go differ\n",
+	}
+	b, err := InitWithRunner(t.TempDir(), "start", "HEAD", "none.json", runner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := b.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if 0 != len(report.Supported) {
+		t.Errorf("Expected no break, got %v", report.Supported)
+	}
+	expected := []IgnoredFile{{Filename: "assets/blob.go", Reason: "binary file"}}
+	if ignored := report.IgnoredFiles(); !reflect.DeepEqual(expected, ignored) {
+		t.Errorf("Expected %v, got %v", expected, ignored)
+	}
+}
+
+func TestReadFileList(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{
+			"a/foo.go": "package a\n\nfunc Foo(a int) {\n}\n",
+			"b/bar.go": "package b\n\nfunc Bar(a int) {\n}\n",
+		},
+		map[string]string{
+			"a/foo.go": "package a\n\nfunc Foo() {\n}\n",
+			"b/bar.go": "package b\n\nfunc Bar() {\n}\n",
+		})
+
+	tests := []struct {
+		name     string
+		fileList string
+		paths    []string
+		expected []string
+	}{
+		{"given files only", "M\ta/foo.go\n", nil, []string{"a/foo.go"}},
+		{"blank lines and CRLF", "\r\nM\ta/foo.go\r\nM\tb/bar.go\r\n", nil, []string{"a/foo.go", "b/bar.go"}},
+		{"restricted to paths", "M\ta/foo.go\nM\tb/bar.go\n", []string{"b/"}, []string{"b/bar.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Init(dir, "start", "HEAD", "none.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := b.ReadFileList(strings.NewReader(tt.fileList)); err != nil {
+				t.Fatal(err)
+			}
+			results, err := b.AnalyzeFiles(tt.paths)
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := make([]string, 0, len(results))
+			for _, result := range results {
+				names = append(names, result.Filename)
+				assertExplanations(t, result.Breaks, "Deletion of parameter")
+			}
+			if !reflect.DeepEqual(tt.expected, names) {
+				t.Errorf("Expected %q, got %q", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestInvalidFileList(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileList string
+	}{
+		{"no tab", "M foo.go\n"},
+		{"no path", "M\t\n"},
+		{"no status", "\tfoo.go\n"},
+		{"rename without new path", "R100\told.go\n"},
+		{"copy without new path", "C75\told.go\n"},
+		{"rename with empty new path", "R100\told.go\t\n"},
+		{"modification with two paths", "M\tfoo.go\tbar.go\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := InitWithRunner(t.TempDir(), "start", "HEAD", "none.json", fakeRunner{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = b.ReadFileList(strings.NewReader(tt.fileList))
+			if err == nil || !strings.HasPrefix(err.Error(), "Invalid name-status line") {
+				t.Fatalf("Expected an invalid line error, got %v", err)
+			}
+			if line := strings.TrimSuffix(tt.fileList, "\n"); !strings.Contains(err.Error(), line) {
+				t.Errorf("Expected the error to name %q, got %v", line, err)
+			}
+		})
+	}
+}
+
+// remoteRunner answers git commands of a clone whose remote branches are only
+// known once fetched
+type remoteRunner struct {
+	fetchErr error
+	fetched  *bool
+	commands *[]string
+}
+
+// Run answers the canned output of a git command
+func (r remoteRunner) Run(ctx context.Context, args ...string) (string, error) {
+	*r.commands = append(*r.commands, strings.Join(args, " "))
+	switch args[0] {
+	case "rev-parse":
+		point := strings.TrimSuffix(args[len(args)-1], "^{commit}")
+		if strings.Contains(point, "/") && !*r.fetched {
+			return "", errors.New("exit status 1")
+		}
+		return point + "\n", nil
+	case "remote":
+		return "origin\n", nil
+	case "fetch":
+		if r.fetchErr != nil {
+			return "", r.fetchErr
+		}
+		*r.fetched = true
+		return "", nil
+	}
+
+	return "", fmt.Errorf("Unexpected git command %v", args)
+}
+
+func TestFetchRemotes(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		endPoint string
+		fetchErr error
+		fetches  bool
+		expected string
+	}{
+		{"fetched then resolved", "fetch.json", "origin/main", nil, true, ""},
+		{"fetch failure", "fetch.json", "origin/main", errors.New("Could not read from remote repository"), true, "Fetching main from origin failed : Could not read from remote repository"},
+		{"not configured", "none.json", "origin/main", nil, false, "The object origin/main doesn't exist"},
+		{"unknown remote", "fetch.json", "upstream/main", nil, false, "The object upstream/main doesn't exist"},
+		{"not a remote branch", "fetch.json", "HEAD", nil, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "fetch.json"), `{"fetchRemotes": true}`)
+			fetched := false
+			commands := make([]string, 0)
+			runner := remoteRunner{fetchErr: tt.fetchErr, fetched: &fetched, commands: &commands}
+
+			_, err := InitWithRunner(dir, "start", tt.endPoint, tt.config, runner)
+
+			message := ""
+			if err != nil {
+				message = err.Error()
+			}
+			if tt.expected != message {
+				t.Errorf("Expected %q, got %q", tt.expected, message)
+			}
+			fetching := "fetch --quiet --no-tags origin +refs/heads/main:refs/remotes/origin/main"
+			if ran := contains(commands, fetching); tt.fetches != ran {
+				t.Errorf("Expected fetching to be %t, got commands %q", tt.fetches, commands)
+			}
+		})
+	}
+}
+
+// contains tells if a string is among strings
+func contains(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+
+	return false
+}
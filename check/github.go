@@ -0,0 +1,58 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReportGitHub displays potentials compatibility breaks as GitHub Actions
+// workflow commands, annotating the pull request diff: errors for hard
+// breaks, warnings for soft ones
+func (b *Break) ReportGitHub() ([]byte, error) {
+	report, err := b.Report()
+	if err != nil {
+		return nil, err
+	}
+
+	return report.GitHub()
+}
+
+// GitHub displays potentials compatibility breaks of the report as GitHub
+// Actions workflow commands
+func (r *BreakReport) GitHub() ([]byte, error) {
+	var annotations strings.Builder
+	for _, fr := range r.Supported {
+		for _, m := range fr.methods {
+			command := "warning"
+			if Hard == m.severity {
+				command = "error"
+			}
+			properties := "file=" + escapedProperty(fr.filename)
+			// Lines of deletions are in the old version, out of the diff annotated
+			if m.after != "" && m.line > 0 {
+				properties += fmt.Sprintf(",line=%d", m.line)
+			}
+			properties += ",title=" + escapedProperty(ruleName(m.displayed()))
+
+			message := m.displayed() + " : " + m.before
+			if m.before == "" {
+				message += m.after
+			} else if m.after != "" {
+				message += " -> " + m.after
+			}
+			annotations.WriteString(fmt.Sprintf("::%s %s::%s\n", command, properties, escapedData(message)))
+		}
+	}
+
+	return []byte(annotations.String()), nil
+}
+
+// escapedData escapes the message of a workflow command
+func escapedData(data string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(data)
+}
+
+// escapedProperty escapes a property value of a workflow command
+func escapedProperty(property string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(escapedData(property))
+}
@@ -0,0 +1,40 @@
+package check
+
+import "testing"
+
+func TestReportGitHub(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{"foo.go": "package foo\n\nfunc Foo(a int, b int) {\n}\n\nfunc Bar() {\n}\n\nfunc Map[T any](s []T) {\n}\n"},
+		map[string]string{"foo.go": "package foo\n\nfunc Foo(a int) {\n}\n\nfunc Map[T comparable](s []T) {\n}\n"})
+	b, err := Init(dir, "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := b.ReportGitHub()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "::error file=foo.go,title=Deletion of method::Deletion of method : func Bar() {\n" +
+		"::error file=foo.go,line=3,title=Deletion of parameter::Deletion of parameter : func Foo(a int, b int) { -> func Foo(a int) {\n" +
+		"::warning file=foo.go,line=6,title=Type parameters changed::Type parameters changed : func Map[T any](s []T) { -> func Map[T comparable](s []T) {\n"
+	if expected != string(output) {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+func TestEscapedProperty(t *testing.T) {
+	tests := []struct {
+		property string
+		expected string
+	}{
+		{"foo.go", "foo.go"},
+		{"a,b:c.go", "a%2Cb%3Ac.go"},
+		{"100%\nsure", "100%25%0Asure"},
+	}
+	for _, tt := range tests {
+		if escaped := escapedProperty(tt.property); tt.expected != escaped {
+			t.Errorf("Expected %q, got %q", tt.expected, escaped)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package check
+
+import (
+	"path"
+	"strings"
+)
+
+// matchGlob reports whether name matches the glob pattern. On top of
+// path.Match syntax, `**` matches any number of directories, and a pattern
+// without `/` is matched against the base name, whatever the directory
+func matchGlob(pattern string, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(name))
+		return matched
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches path segments one by one, `**` absorbing zero or more of them
+func matchSegments(patterns []string, names []string) bool {
+	if 0 == len(patterns) {
+		return 0 == len(names)
+	}
+	if "**" == patterns[0] {
+		for i := 0; i <= len(names); i++ {
+			if matchSegments(patterns[1:], names[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if 0 == len(names) {
+		return false
+	}
+	matched, _ := path.Match(patterns[0], names[0])
+
+	return matched && matchSegments(patterns[1:], names[1:])
+}
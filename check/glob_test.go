@@ -0,0 +1,25 @@
+package check
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*_test.go", "foo_test.go", true},
+		{"*_test.go", "pkg/sub/foo_test.go", true},
+		{"*_test.go", "pkg/foo.go", false},
+		{"**/vendor/**", "vendor/lib/foo.go", true},
+		{"**/vendor/**", "pkg/vendor/lib/foo.go", true},
+		{"**/vendor/**", "pkg/vendors/foo.go", false},
+		{"pkg/*.go", "pkg/foo.go", true},
+		{"pkg/*.go", "pkg/sub/foo.go", false},
+	}
+	for _, tt := range tests {
+		if matched := matchGlob(tt.pattern, tt.name); tt.expected != matched {
+			t.Errorf("Expected %v for %s against %s, got %v", tt.expected, tt.name, tt.pattern, matched)
+		}
+	}
+}
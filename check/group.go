@@ -0,0 +1,58 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+)
+
+// BreakGroup is a potential compatibility break repeated across files, with
+// the same explanation and the same signatures
+type BreakGroup struct {
+	method    method
+	filenames []string
+}
+
+// Groups gathers identical potentials compatibility breaks of a report, in
+// order of first appearance
+func (r *BreakReport) Groups() []BreakGroup {
+	groups := make([]BreakGroup, 0)
+	positions := make(map[string]int)
+	for _, fr := range r.Supported {
+		for _, m := range fr.methods {
+			key := m.explanation + "\x00" + m.before + "\x00" + m.after
+			position, known := positions[key]
+			if !known {
+				positions[key] = len(groups)
+				groups = append(groups, BreakGroup{method: m})
+				position = len(groups) - 1
+			}
+			group := &groups[position]
+			if 0 == len(group.filenames) || group.filenames[len(group.filenames)-1] != fr.filename {
+				group.filenames = append(group.filenames, fr.filename)
+			}
+		}
+	}
+
+	return groups
+}
+
+// Filenames are the files where the break occurs
+func (g *BreakGroup) Filenames() []string {
+	return g.filenames
+}
+
+// Report displays a BreakGroup, with the count of files affected
+func (g *BreakGroup) Report() string {
+	var change string
+	switch {
+	case "" == g.method.after:
+		change = color.RedString(g.method.before)
+	case "" == g.method.before:
+		change = color.GreenString(g.method.after)
+	default:
+		change = color.RedString(g.method.before) + " -> " + color.GreenString(g.method.after)
+	}
+
+	return fmt.Sprintf(">> %s : %s (%d file(s))", coloredExplanation(g.method), change, len(g.filenames))
+}
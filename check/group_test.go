@@ -0,0 +1,46 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestGroups(t *testing.T) {
+	color.NoColor = true
+	dir := newRepo(t,
+		map[string]string{
+			"a/foo.go": "package foo\n\nfunc Foo(a int, b int) {\n}\n",
+			"b/foo.go": "package foo\n\nfunc Foo(a int, b int) {\n}\n",
+			"c/foo.go": "package foo\n\nfunc Foo(a int, b int) {\n}\n\nfunc Bar() {\n}\n",
+		},
+		map[string]string{
+			"a/foo.go": "package foo\n\nfunc Foo(a int) {\n}\n",
+			"b/foo.go": "package foo\n\nfunc Foo(a int) {\n}\n",
+			"c/foo.go": "package foo\n\nfunc Foo(a int) {\n}\n",
+		})
+	b, err := Init(dir, "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := b.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups := report.Groups()
+	if 2 != len(groups) {
+		t.Fatalf("Expected 2 groups, got %d", len(groups))
+	}
+	expected := [][]string{{"a/foo.go", "b/foo.go", "c/foo.go"}, {"c/foo.go"}}
+	for i, group := range groups {
+		if !reflect.DeepEqual(expected[i], group.Filenames()) {
+			t.Errorf("Expected %q, got %q", expected[i], group.Filenames())
+		}
+	}
+	reported := ">> Deletion of parameter : func Foo(a int, b int) { -> func Foo(a int) { (3 file(s))"
+	if got := groups[0].Report(); reported != got {
+		t.Errorf("Expected %q, got %q", reported, got)
+	}
+}
@@ -0,0 +1,134 @@
+package check
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// compared returns potentials compatibility breaks between two versions of a
+// file of a langage
+func compared(t *testing.T, language string, before string, after string) []MethodBreak {
+	t.Helper()
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "before."+language)
+	newPath := filepath.Join(dir, "after."+language)
+	writeFile(t, oldPath, before)
+	writeFile(t, newPath, after)
+	breaks, err := CompareFiles(oldPath, newPath, language)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return breaks
+}
+
+// explanationsOf lists explanations of breaks, in order
+func explanationsOf(breaks []MethodBreak) []string {
+	explanations := make([]string, 0, len(breaks))
+	for _, b := range breaks {
+		explanations = append(explanations, b.Explanation)
+	}
+
+	return explanations
+}
+
+// assertExplanations fails if breaks aren't explained as expected, in order
+func assertExplanations(t *testing.T, breaks []MethodBreak, expected ...string) {
+	t.Helper()
+	if expected == nil {
+		expected = []string{}
+	}
+	if explanations := explanationsOf(breaks); !reflect.DeepEqual(explanations, expected) {
+		t.Errorf("Expected %q, got %q", expected, explanations)
+	}
+}
+
+// newRepo builds a git repository whose tag "start" holds before files, and
+// HEAD after ones. An empty content removes a file
+func newRepo(t testing.TB, before map[string]string, after map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	writeFiles(t, dir, before)
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "--allow-empty", "-m", "start")
+	git(t, dir, "tag", "start")
+	writeFiles(t, dir, after)
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "--allow-empty", "-m", "end")
+
+	return dir
+}
+
+// git runs a git command in a directory, failing the test on error
+func git(t testing.TB, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=check-break", "GIT_AUTHOR_EMAIL=check-break@example.com",
+		"GIT_COMMITTER_NAME=check-break", "GIT_COMMITTER_EMAIL=check-break@example.com",
+		"GIT_CONFIG_NOSYSTEM=1", "HOME="+dir)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v : %s", args, out)
+	}
+
+	return string(out)
+}
+
+// writeFiles writes files of a directory, an empty content removing a file
+func writeFiles(t testing.TB, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if "" == content {
+			if err := os.Remove(path); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		writeFile(t, path, content)
+	}
+}
+
+// writeFile writes a file, its directories included
+func writeFile(t testing.TB, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// analyzed returns potentials compatibility breaks between tag "start" and
+// HEAD of a repository, with a config file, if any
+func analyzed(t *testing.T, dir string, configFilename string) []FileResult {
+	t.Helper()
+	b, err := Init(dir, "start", "HEAD", configFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := b.Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return results
+}
+
+// breaksOf returns breaks of a file among results, nil if none
+func breaksOf(results []FileResult, filename string) []MethodBreak {
+	for _, result := range results {
+		if result.Filename == filename {
+			return result.Breaks
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package check
+
+import (
+	"encoding/xml"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// ReportJUnit displays analysed files as JUnit XML test cases, failing with
+// their potentials compatibility breaks
+func (b *Break) ReportJUnit() ([]byte, error) {
+	report, err := b.Report()
+	if err != nil {
+		return nil, err
+	}
+
+	return report.JUnit()
+}
+
+// JUnit displays files analysed for the report as JUnit XML test cases,
+// failing with their potentials compatibility breaks
+func (r *BreakReport) JUnit() ([]byte, error) {
+	breaks := make(map[string][]method)
+	for _, fr := range r.Supported {
+		breaks[fr.filename] = fr.methods
+	}
+	suite := junitTestSuite{
+		Name:  "check-break",
+		Cases: make([]junitTestCase, 0, len(r.analysed)),
+	}
+	for _, filename := range r.analysed {
+		testCase := junitTestCase{
+			Name:      filename,
+			ClassName: "check-break",
+		}
+		for _, m := range breaks[filename] {
+			content := m.before
+			if m.before == "" {
+				content = m.after
+			} else if m.after != "" {
+				content += " -> " + m.after
+			}
+			testCase.Failures = append(testCase.Failures, junitFailure{
+				Message: m.displayed(),
+				Type:    m.severity.String(),
+				Content: content,
+			})
+		}
+		if 0 != len(testCase.Failures) {
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Tests = len(suite.Cases)
+
+	output, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), output...), nil
+}
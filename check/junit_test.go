@@ -0,0 +1,52 @@
+package check
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestReportJUnit(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{
+			"clean.go":  "package foo\n\nfunc Clean(a int) {\n}\n",
+			"broken.go": "package foo\n\nfunc Broken(a int) {\n}\n",
+		},
+		map[string]string{
+			"clean.go":  "package foo\n\nfunc Clean(a int) {\n\treturn\n}\n",
+			"broken.go": "package foo\n\nfunc Broken() {\n}\n",
+		})
+	b, err := Init(dir, "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := b.ReportJUnit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(output, &suites); err != nil {
+		t.Fatal(err)
+	}
+	if 1 != len(suites.Suites) {
+		t.Fatalf("Expected a single suite, got %s", output)
+	}
+	suite := suites.Suites[0]
+	if 2 != suite.Tests || 1 != suite.Failures || 2 != len(suite.Cases) {
+		t.Fatalf("Expected 2 tests, 1 failing, got %s", output)
+	}
+	for _, testCase := range suite.Cases {
+		switch testCase.Name {
+		case "clean.go":
+			if 0 != len(testCase.Failures) {
+				t.Errorf("Expected clean.go to pass, got %v", testCase.Failures)
+			}
+		case "broken.go":
+			if 1 != len(testCase.Failures) || "Deletion of parameter" != testCase.Failures[0].Message || "hard" != testCase.Failures[0].Type {
+				t.Errorf("Unexpected failures of broken.go %v", testCase.Failures)
+			}
+		default:
+			t.Errorf("Unexpected test case %s", testCase.Name)
+		}
+	}
+}
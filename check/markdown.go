@@ -0,0 +1,52 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReportMarkdown displays potentials compatibility breaks as Markdown tables
+// grouped by file, to be posted as a pull request comment
+func (b *Break) ReportMarkdown() ([]byte, error) {
+	report, err := b.Report()
+	if err != nil {
+		return nil, err
+	}
+
+	return report.Markdown()
+}
+
+// Markdown displays potentials compatibility breaks of the report as
+// Markdown tables grouped by file
+func (r *BreakReport) Markdown() ([]byte, error) {
+	var md strings.Builder
+	md.WriteString("## Potentials compatibility breaks\n\n")
+	if 0 == len(r.Supported) {
+		md.WriteString("No compatibility break\n")
+		return []byte(md.String()), nil
+	}
+	for _, fr := range r.Supported {
+		md.WriteString("### `" + fr.filename + "`\n\n")
+		if fr.deleted {
+			md.WriteString(fmt.Sprintf("File removed, %d public methods affected\n\n", len(fr.methods)))
+			continue
+		}
+		md.WriteString("| Method | Change | Before | After |\n")
+		md.WriteString("| --- | --- | --- | --- |\n")
+		for _, m := range fr.methods {
+			md.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", inlineCode(m.commonFactor), m.displayed(), inlineCode(m.before), inlineCode(m.after)))
+		}
+		md.WriteString("\n")
+	}
+
+	return []byte(md.String()), nil
+}
+
+// inlineCode formats a signature as Markdown inline code, usable in a table
+func inlineCode(code string) string {
+	if "" == code {
+		return ""
+	}
+
+	return "`" + strings.Replace(code, "|", "\\|", -1) + "`"
+}
@@ -0,0 +1,44 @@
+package check
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReportMarkdown(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{
+			"foo.go": "package foo\n\nfunc Foo(a int, b int) {\n}\n",
+			"bar.go": "package foo\n\nfunc Bar() {\n}\n\nfunc Baz() {\n}\n",
+		},
+		map[string]string{
+			"foo.go": "package foo\n\nfunc Foo(a int) {\n}\n",
+			"bar.go": "",
+		})
+	b, err := Init(dir, "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := b.ReportMarkdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	markdown := string(output)
+	for _, expected := range []string{
+		"## Potentials compatibility breaks\n",
+		"### `bar.go`\n\nFile removed, 2 public methods affected\n",
+		"### `foo.go`\n\n| Method | Change | Before | After |\n| --- | --- | --- | --- |\n",
+		"| `func Foo(` | Deletion of parameter | `func Foo(a int, b int) {` | `func Foo(a int) {` |\n",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Expected %q in %s", expected, markdown)
+		}
+	}
+}
+
+func TestInlineCodeEscapesPipes(t *testing.T) {
+	if code := inlineCode("a | b"); "`a \\| b`" != code {
+		t.Errorf("Unexpected inline code %s", code)
+	}
+}
@@ -0,0 +1,83 @@
+package check
+
+// catalogs are the translations of explanations by locale, keyed by message
+// ID. Message IDs are the SARIF rule IDs of explanations
+// (`deletion-of-method`), english explanations being the default
+var catalogs = map[string]map[string]string{
+	"en": {},
+	"fr": {
+		"added-checked-exception":                  "Exception vérifiée ajoutée",
+		"adding-a-parameter-without-default-value": "Ajout d'un paramètre sans valeur par défaut",
+		"default-value-changed":                    "Valeur par défaut modifiée",
+		"deletion-of-default-parameter":            "Suppression d'un paramètre par défaut",
+		"deletion-of-field":                        "Suppression de champ",
+		"deletion-of-method":                       "Suppression de méthode",
+		"deletion-of-overload":                     "Suppression de surcharge",
+		"deletion-of-parameter":                    "Suppression de paramètre",
+		"enum-value-removed":                       "Valeur d'énumération supprimée",
+		"field-type-changed":                       "Type de champ modifié",
+		"function-converted-to-method":             "Fonction convertie en méthode",
+		"made-final/non-overridable":               "Rendue finale/non surchargeable",
+		"method-added-to-interface":                "Méthode ajoutée à l'interface",
+		"method-converted-to-function":             "Méthode convertie en fonction",
+		"keyword-parameter-made-positional":        "Paramètre nommé rendu positionnel",
+		"method-renamed":                           "Méthode renommée",
+		"mutability-contract-changed":              "Contrat de mutabilité modifié",
+		"parameter-label-changed":                  "Étiquette de paramètre modifiée",
+		"parameter-made-keyword":                   "Paramètre rendu nommé",
+		"parameter-made-variadic":                  "Paramètre rendu variadique",
+		"parameter-removed-and-parameter-added":    "Paramètre supprimé et paramètre ajouté",
+		"parameter-renamed":                        "Paramètre renommé",
+		"parameter-type-changed":                   "Type de paramètre modifié",
+		"parameters-reordered":                     "Paramètres réordonnés",
+		"public-constant-changed":                  "Constante publique modifiée",
+		"public-constant-removed":                  "Constante publique supprimée",
+		"public-type-removed":                      "Type public supprimé",
+		"receiver-type-changed":                    "Type du receveur modifié",
+		"reduced-visibility":                       "Visibilité réduite",
+		"removed-without-prior-deprecation":        "Supprimée sans dépréciation préalable",
+		"return-type-changed":                      "Type de retour modifié",
+		"return-type-narrowed":                     "Type de retour restreint",
+		"return-type-widened":                      "Type de retour élargi",
+		"type-definition-changed":                  "Définition de type modifiée",
+		"type-parameters-changed":                  "Paramètres de type modifiés",
+		"unknown-signature-change":                 "Modification de signature inconnue",
+		"variadic-parameter-removed":               "Paramètre variadique supprimé",
+	},
+}
+
+// isMessageID tells if id is the message ID of an explanation, the french
+// catalog translating all of them
+func isMessageID(id string) bool {
+	_, known := catalogs["fr"][id]
+
+	return known
+}
+
+// message translates an explanation according to the config, its details
+// between parenthesis kept. Messages of the config override the catalog of
+// its locale
+func (c *config) message(explanation string) string {
+	if c == nil {
+		return explanation
+	}
+	id := ruleID(explanation)
+	translated, known := c.Messages[id]
+	if !known {
+		translated, known = catalogs[c.Locale][id]
+	}
+	if !known {
+		return explanation
+	}
+
+	return translated + explanation[len(ruleName(explanation)):]
+}
+
+// displayed is the explanation of a method, as translated for the report
+func (m *method) displayed() string {
+	if "" == m.message {
+		return m.explanation
+	}
+
+	return m.message
+}
@@ -0,0 +1,75 @@
+package check
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestMessage(t *testing.T) {
+	french := &config{Locale: "fr"}
+	overridden := &config{Locale: "fr", Messages: map[string]string{"deletion-of-method": "Méthode disparue"}}
+	tests := []struct {
+		name        string
+		conf        *config
+		explanation string
+		expected    string
+	}{
+		{"no config", nil, "Deletion of method", "Deletion of method"},
+		{"english", &config{}, "Deletion of method", "Deletion of method"},
+		{"french", french, "Deletion of method", "Suppression de méthode"},
+		{"french details", french, "Reduced visibility (public -> private)", "Visibilité réduite (public -> private)"},
+		{"french unknown", french, "Something new", "Something new"},
+		{"overridden", overridden, "Deletion of method", "Méthode disparue"},
+		{"not overridden", overridden, "Deletion of parameter", "Suppression de paramètre"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if message := tt.conf.message(tt.explanation); tt.expected != message {
+				t.Errorf("Expected %q, got %q", tt.expected, message)
+			}
+		})
+	}
+}
+
+func TestFrenchReport(t *testing.T) {
+	color.NoColor = true
+	dir := newRepo(t,
+		map[string]string{
+			"check-break.json": `{"locale": "fr"}`,
+			"foo.go":           "package foo\n\nfunc Foo(a int, b int) {\n}\n\nfunc Bar() {\n}\n",
+		},
+		map[string]string{"foo.go": "package foo\n\nfunc Foo(a int) {\n}\n"})
+	b, err := Init(dir, "start", "HEAD", "check-break.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := b.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := report.Supported[0].Report()
+	for _, expected := range []string{"Suppression de méthode", "Suppression de paramètre"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in %q", expected, text)
+		}
+	}
+	// Machine formats keep stable english explanations
+	output, err := report.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(output), `"explanation":"Deletion of method"`) {
+		t.Errorf("Expected english explanations, got %s", output)
+	}
+}
+
+func TestUnknownLocale(t *testing.T) {
+	dir := newRepo(t, map[string]string{"check-break.json": `{"locale": "xx"}`}, map[string]string{})
+
+	if _, err := Init(dir, "start", "HEAD", "check-break.json"); err == nil || !strings.Contains(err.Error(), "Unknown locale xx") {
+		t.Errorf("Expected an unknown locale, got %v", err)
+	}
+}
@@ -1,7 +1,11 @@
 package check
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/fatih/color"
 )
@@ -11,63 +15,373 @@ type BreakReport struct {
 	Supported  []FileReport
 	Ignored    []file
 	Exclusions []string
+	// analysed are names of all files analysed, with or without breaks
+	analysed []string
+	// excluded are files matching an exclusion, supported or not
+	excluded []file
 }
 
 // Report displays a BreakReport
 func (b *Break) Report() (*BreakReport, error) {
-	f, err := diffFileList(b.startPoint, b.endPoint)
+	return b.ReportContext(context.Background())
+}
+
+// ReportContext is Report, stopping as soon as ctx is done
+func (b *Break) ReportContext(ctx context.Context) (*BreakReport, error) {
+	analysables, ignored, excluded, err := b.partition(ctx)
 	if err != nil {
 		return nil, err
 	}
-	supported, ignored := files(f, *b)
-	analysables := b.filter(supported)
-	ignored = b.filter(ignored)
 
 	filesReports := make([]FileReport, 0)
+	analysed := make([]string, 0, len(analysables))
 	for _, file := range analysables {
-		methods, _ := file.breaks()
+		analysed = append(analysed, file.name)
+		found, _ := file.breaks()
+		methods := make([]method, 0, len(*found))
+		for _, m := range *found {
+			if !b.config.reports(m.severity) {
+				continue
+			}
+			m.message = b.config.message(m.explanation)
+			m.failing = b.fails(m)
+			methods = append(methods, m)
+		}
 
-		if 0 != len(*methods) {
+		if 0 != len(methods) {
 			fileReport := FileReport{
-				filename: file.name,
-				methods:  *methods,
+				filename:     file.name,
+				previousName: file.previousName,
+				typeFile:     file.typeFile,
+				methods:      methods,
+				deleted:      file.isDeleted(),
 			}
 			filesReports = append(filesReports, fileReport)
 		}
 	}
 
+	sortReports(filesReports)
+
 	return &BreakReport{
 		Supported:  filesReports,
 		Ignored:    ignored,
 		Exclusions: b.exclusions(),
+		analysed:   analysed,
+		excluded:   excluded,
 	}, nil
 }
 
+// CountByLanguage is the number of potentials compatibility breaks per type
+// of file (`go`, `php`…)
+func (r *BreakReport) CountByLanguage() map[string]int {
+	counts := make(map[string]int)
+	for _, fr := range r.Supported {
+		counts[fr.typeFile] += fr.Count()
+	}
+
+	return counts
+}
+
+// LanguagesSummary displays counts of potentials compatibility breaks per
+// type of file, by name (`go: 3, php: 1`)
+func (r *BreakReport) LanguagesSummary() string {
+	counts := r.CountByLanguage()
+	languages := make([]string, 0, len(counts))
+	for language := range counts {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	summary := make([]string, 0, len(languages))
+	for _, language := range languages {
+		summary = append(summary, fmt.Sprintf("%s: %d", language, counts[language]))
+	}
+
+	return strings.Join(summary, ", ")
+}
+
+// sortReports orders reports by file name and their breaks by common factor,
+// for a stable output whatever the order of the diff
+func sortReports(reports []FileReport) {
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].filename < reports[j].filename
+	})
+	for _, fr := range reports {
+		methods := fr.methods
+		sort.SliceStable(methods, func(i, j int) bool {
+			if methods[i].commonFactor != methods[j].commonFactor {
+				return methods[i].commonFactor < methods[j].commonFactor
+			}
+			if methods[i].before != methods[j].before {
+				return methods[i].before < methods[j].before
+			}
+			return methods[i].after < methods[j].after
+		})
+	}
+}
+
+// sortFiles orders files by name
+func sortFiles(files []file) {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].name < files[j].name
+	})
+}
+
+// partition splits changed files into analysables, ignored and excluded ones
+func (b *Break) partition(ctx context.Context) ([]file, []file, []file, error) {
+	f, err := b.diffFileList(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	supported, ignored, err := files(ctx, f, *b)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	analysables, excluded := b.filter(supported)
+	ignored, excludedIgnored := b.filter(ignored)
+	excluded = append(excluded, excludedIgnored...)
+	for _, files := range [][]file{analysables, ignored, excluded} {
+		sortFiles(files)
+	}
+
+	return analysables, ignored, excluded, nil
+}
+
+// FileList is the partition of changed files, as the analysis sees it
+type FileList struct {
+	Analysable []string
+	Ignored    []IgnoredFile
+}
+
+// ListFiles tells which changed files would be analysed and which would be
+// ignored, without looking for breaks
+func (b *Break) ListFiles() (*FileList, error) {
+	analysables, ignored, excluded, err := b.partition(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	list := &FileList{
+		Analysable: make([]string, 0, len(analysables)),
+		Ignored:    ignoredFiles(ignored, excluded),
+	}
+	for _, f := range analysables {
+		list.Analysable = append(list.Analysable, f.name)
+	}
+
+	return list, nil
+}
+
+// IgnoredFile is a changed file not analysed, and why
+type IgnoredFile struct {
+	Filename string
+	Reason   string
+}
+
+// IgnoredFiles lists changed files not analysed, unsupported ones first,
+// then excluded ones
+func (r *BreakReport) IgnoredFiles() []IgnoredFile {
+	return ignoredFiles(r.Ignored, r.excluded)
+}
+
+// ignoredFiles describes files not analysed, unsupported ones first
+func ignoredFiles(unsupported []file, excluded []file) []IgnoredFile {
+	described := make([]IgnoredFile, 0, len(unsupported)+len(excluded))
+	for _, files := range [][]file{unsupported, excluded} {
+		for _, f := range files {
+			described = append(described, IgnoredFile{Filename: f.name, Reason: f.ignoredReason})
+		}
+	}
+
+	return described
+}
+
 // FileReport is a pool of potentials compatibility breaks
 type FileReport struct {
 	methods  []method
 	filename string
+	// previousName is the name of the file at the starting point, if moved
+	previousName string
+	typeFile     string
+	deleted      bool
 }
 
 // Report displays a FileReport and its potentials compatibility breaks
 func (fr *FileReport) Report() string {
 	report := ">> " + color.CyanString(fr.filename+" :")
+	if "" != fr.previousName {
+		// Signatures are compared across the move
+		report = ">> " + color.CyanString(fr.filename) + " (moved from " + fr.previousName + ") :"
+	}
 	for _, method := range fr.methods {
 		var change string
 		report += "\n"
 		beforeFormatted := color.RedString(method.before)
 		afterFormatted := color.GreenString(method.after)
-		if "" == method.after {
+		switch {
+		case "" == method.after:
 			change = beforeFormatted
-		} else {
+		case "" == method.before:
+			change = afterFormatted
+		default:
 			change = beforeFormatted + " -> " + afterFormatted
 		}
-		report += method.explanation + " : " + change
+		report += coloredExplanation(method) + " : " + change
 	}
 
 	return report + "\n"
 }
 
+// coloredExplanation colors the explanation of a method by type of change:
+// red for deletions, yellow for additions
+func coloredExplanation(m method) string {
+	lowered := strings.ToLower(m.explanation)
+	if strings.Contains(lowered, "deletion") || strings.Contains(lowered, "removed") {
+		return color.RedString(m.displayed())
+	}
+	if strings.Contains(lowered, "adding") || strings.Contains(lowered, "added") {
+		return color.YellowString(m.displayed())
+	}
+
+	return m.displayed()
+}
+
+// Summary displays a FileReport as a count of its potentials compatibility breaks
+func (fr *FileReport) Summary() string {
+	return fmt.Sprintf(">> %s %d potential(s) break(s)", color.CyanString(fr.filename+" :"), fr.Count())
+}
+
+// Count is the number of potentials compatibility breaks of a FileReport
+func (fr *FileReport) Count() int {
+	return len(fr.methods)
+}
+
 func (f *file) Report() string {
+	if "" != f.ignoredReason {
+		return fmt.Sprint(">> ", color.CyanString(f.name), " (", f.ignoredReason, ")")
+	}
+
 	return fmt.Sprint(">> ", color.CyanString(f.name))
 }
+
+// jsonBreak is a potential compatibility break, as serialized in JSON
+type jsonBreak struct {
+	File         string `json:"file"`
+	Language     string `json:"language"`
+	Before       string `json:"before"`
+	After        string `json:"after"`
+	CommonFactor string `json:"commonFactor"`
+	Explanation  string `json:"explanation"`
+	Severity     string `json:"severity"`
+	Line         int    `json:"line,omitempty"`
+}
+
+// ReportJSON serializes potentials compatibility breaks in JSON
+func (b *Break) ReportJSON() ([]byte, error) {
+	report, err := b.Report()
+	if err != nil {
+		return nil, err
+	}
+
+	return report.JSON()
+}
+
+// JSON serializes potentials compatibility breaks of the report in JSON
+func (r *BreakReport) JSON() ([]byte, error) {
+	breaks := make([]jsonBreak, 0)
+	for _, result := range r.results() {
+		for _, m := range result.Breaks {
+			breaks = append(breaks, jsonBreak{
+				File:         result.Filename,
+				Language:     result.Language,
+				Before:       m.Before,
+				After:        m.After,
+				CommonFactor: m.CommonFactor,
+				Explanation:  m.Explanation,
+				Severity:     m.Severity.String(),
+				Line:         m.Line,
+			})
+		}
+	}
+
+	return json.Marshal(breaks)
+}
+
+// FileResult holds potentials compatibility breaks of a file, for programs
+// embedding check-break
+type FileResult struct {
+	Filename string
+	// PreviousFilename is the name of the file at the starting point, if moved
+	PreviousFilename string
+	// Language is the type of the file (`go`, `php`…), as in config
+	Language string
+	Breaks   []MethodBreak
+}
+
+// MethodBreak is a potential compatibility break on a public method
+type MethodBreak struct {
+	Before       string
+	After        string
+	CommonFactor string
+	Explanation  string
+	Severity     Severity
+	// Line is the line of After in the new version, or of Before in the old
+	// one for deletions, 0 if unknown
+	Line int
+}
+
+// Analyze returns potentials compatibility breaks, file by file
+func (b *Break) Analyze() ([]FileResult, error) {
+	return b.AnalyzeContext(context.Background())
+}
+
+// AnalyzeFiles is Analyze, restricted to some paths (files or directories).
+// Paths unchanged between the two points have no result
+func (b *Break) AnalyzeFiles(paths []string) ([]FileResult, error) {
+	restricted := *b
+	restricted.paths = paths
+
+	return restricted.Analyze()
+}
+
+// AnalyzeContext is Analyze, stopping as soon as ctx is done. Running git
+// processes are killed then
+func (b *Break) AnalyzeContext(ctx context.Context) ([]FileResult, error) {
+	report, err := b.ReportContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return report.results(), nil
+}
+
+// results exposes files of the report having potentials compatibility breaks
+// to embedding programs
+func (r *BreakReport) results() []FileResult {
+	results := make([]FileResult, 0, len(r.Supported))
+	for _, fr := range r.Supported {
+		results = append(results, FileResult{
+			Filename:         fr.filename,
+			PreviousFilename: fr.previousName,
+			Language:         fr.typeFile,
+			Breaks:           methodBreaks(fr.methods),
+		})
+	}
+
+	return results
+}
+
+// methodBreaks exposes potentials compatibility breaks to embedding programs
+func methodBreaks(methods []method) []MethodBreak {
+	breaks := make([]MethodBreak, 0, len(methods))
+	for _, m := range methods {
+		breaks = append(breaks, MethodBreak{
+			Before:       m.before,
+			After:        m.after,
+			CommonFactor: m.commonFactor,
+			Explanation:  m.explanation,
+			Severity:     m.severity,
+			Line:         m.line,
+		})
+	}
+
+	return breaks
+}
@@ -0,0 +1,558 @@
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// breakingRepo is a repository where a go function lost a parameter and
+// another one has been removed
+func breakingRepo(t *testing.T) string {
+	return newRepo(t,
+		map[string]string{"foo.go": "package foo\n\nfunc Foo(a int, b int) {\n}\n\nfunc Bar() {\n}\n"},
+		map[string]string{"foo.go": "package foo\n\nfunc Foo(a int) {\n}\n"})
+}
+
+func TestReportJSON(t *testing.T) {
+	b, err := Init(breakingRepo(t), "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := b.ReportJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var breaks []map[string]interface{}
+	if err := json.Unmarshal(output, &breaks); err != nil {
+		t.Fatal(err)
+	}
+	expected := []map[string]interface{}{
+		{
+			"file":         "foo.go",
+			"language":     "go",
+			"before":       "func Bar() {",
+			"after":        "",
+			"commonFactor": "func Bar(",
+			"explanation":  "Deletion of method",
+			"severity":     "hard",
+			"line":         float64(6),
+		},
+		{
+			"file":         "foo.go",
+			"language":     "go",
+			"before":       "func Foo(a int, b int) {",
+			"after":        "func Foo(a int) {",
+			"commonFactor": "func Foo(",
+			"explanation":  "Deletion of parameter",
+			"severity":     "hard",
+			"line":         float64(3),
+		},
+	}
+	if !reflect.DeepEqual(breaks, expected) {
+		t.Errorf("Expected %v, got %v", expected, breaks)
+	}
+}
+
+func TestBreakLines(t *testing.T) {
+	padding := strings.Repeat("// padding\n", 10)
+	dir := newRepo(t,
+		map[string]string{"foo.go": "package foo\n\nfunc Foo(a int, b int) {\n}\n" + padding + "func Bar() {\n}\n"},
+		map[string]string{"foo.go": "package foo\n\nimport \"fmt\"\n\nvar _ = fmt.Sprint\n\n" + padding + "func Foo(a int) {\n\tfmt.Println(a)\n}\n"})
+
+	results := analyzed(t, dir, "none.json")
+	breaks := breaksOf(results, "foo.go")
+	assertExplanations(t, breaks, "Deletion of method", "Deletion of parameter")
+	// Deletions are located in the old version, changes in the new one
+	for i, expected := range []int{15, 17} {
+		if i < len(breaks) && expected != breaks[i].Line {
+			t.Errorf("Expected line %d of %q, got %d", expected, breaks[i].Before, breaks[i].Line)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	results := analyzed(t, breakingRepo(t), "none.json")
+
+	if 1 != len(results) || "foo.go" != results[0].Filename || "go" != results[0].Language {
+		t.Fatalf("Expected results of foo.go, got %v", results)
+	}
+	assertExplanations(t, results[0].Breaks, "Deletion of method", "Deletion of parameter")
+	if Hard != results[0].Breaks[0].Severity || 6 != results[0].Breaks[0].Line {
+		t.Errorf("Unexpected break %v", results[0].Breaks[0])
+	}
+}
+
+// manyFilesRepo is a repository where a go function lost a parameter in
+// several files
+func manyFilesRepo(t testing.TB, count int) string {
+	before := map[string]string{
+		"w1.json": `{"workers": 1}`,
+		"w8.json": `{"workers": 8}`,
+	}
+	after := make(map[string]string)
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("pkg%d/foo.go", i)
+		before[name] = "package foo\n\nfunc Foo(a int, b int) {\n}\n"
+		after[name] = "package foo\n\nfunc Foo(a int) {\n}\n"
+	}
+
+	return newRepo(t, before, after)
+}
+
+func TestConcurrencyKeepsOrdering(t *testing.T) {
+	dir := manyFilesRepo(t, 20)
+
+	serial := analyzed(t, dir, "w1.json")
+	concurrent := analyzed(t, dir, "w8.json")
+	if 20 != len(serial) || !reflect.DeepEqual(serial, concurrent) {
+		t.Errorf("Expected the same results, got %v and %v", serial, concurrent)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	dir := manyFilesRepo(t, 12)
+	unobserved := analyzed(t, dir, "w8.json")
+
+	b, err := Init(dir, "start", "HEAD", "w8.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]int)
+	counts := make([]int, 0)
+	b.OnProgress(func(filename string, processed int, total int) {
+		seen[filename]++
+		counts = append(counts, processed)
+		if 12 != total {
+			t.Errorf("Expected a total of 12, got %d", total)
+		}
+	})
+	results, err := b.Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(unobserved, results) {
+		t.Errorf("Expected the same results, got %v and %v", unobserved, results)
+	}
+	if 12 != len(seen) {
+		t.Errorf("Expected 12 files processed, got %v", seen)
+	}
+	for filename, calls := range seen {
+		if 1 != calls {
+			t.Errorf("Expected %s to be processed once, got %d", filename, calls)
+		}
+	}
+	for i, count := range counts {
+		if i+1 != count {
+			t.Errorf("Expected a running count, got %v", counts)
+			break
+		}
+	}
+}
+
+// cancellingRunner runs git commands, cancelling the analysis once after
+// calls of them
+type cancellingRunner struct {
+	runner GitRunner
+	after  int
+	cancel context.CancelFunc
+	calls  *int32
+}
+
+// Run runs a git command, then cancels if enough of them ran
+func (r cancellingRunner) Run(ctx context.Context, args ...string) (string, error) {
+	out, err := r.runner.Run(ctx, args...)
+	if atomic.AddInt32(r.calls, 1) == int32(r.after) {
+		r.cancel()
+	}
+
+	return out, err
+}
+
+func TestResultLanguages(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{
+			"a.go":      "package foo\n\nfunc Foo(a int) {\n}\n",
+			"b.php":     "<?php\nfunction foo($a) {\n}\n",
+			"c.py":      "def foo(a):\n    pass\n",
+			"d.PY":      "def foo(a):\n    pass\n",
+			"bin/tools": "#!/usr/bin/env python3\ndef foo(a):\n    pass\n",
+		},
+		map[string]string{
+			"a.go":      "package foo\n\nfunc Foo() {\n}\n",
+			"b.php":     "<?php\nfunction foo() {\n}\n",
+			"c.py":      "def foo():\n    pass\n",
+			"d.PY":      "def foo():\n    pass\n",
+			"bin/tools": "#!/usr/bin/env python3\ndef foo():\n    pass\n",
+		})
+
+	languages := make(map[string]string)
+	for _, result := range analyzed(t, dir, "none.json") {
+		languages[result.Filename] = result.Language
+	}
+	expected := map[string]string{"a.go": "go", "b.php": "php", "c.py": "py", "d.PY": "py", "bin/tools": "py"}
+	if !reflect.DeepEqual(expected, languages) {
+		t.Errorf("Expected %v, got %v", expected, languages)
+	}
+}
+
+func TestMovedAndModified(t *testing.T) {
+	color.NoColor = true
+	content := "package foo\n\nfunc Foo(a int) {\n}\n\nfunc Bar(b string) {\n}\n\nfunc Baz(c bool) {\n}\n"
+	dir := newRepo(t,
+		map[string]string{"a/foo.go": content},
+		map[string]string{"a/foo.go": "", "b/foo.go": strings.Replace(content, "Foo(a int)", "Foo(a int, x int)", 1)})
+	b, err := Init(dir, "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := b.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if 1 != len(report.Supported) || 1 != report.Count(Soft) {
+		t.Fatalf("Expected a single break, got %v", report.Supported)
+	}
+	expected := ">> b/foo.go (moved from a/foo.go) :"
+	if text := report.Supported[0].Report(); !strings.HasPrefix(text, expected) || !strings.Contains(text, "Adding a parameter without default value") {
+		t.Errorf("Expected %q, got %q", expected, text)
+	}
+}
+
+func TestConcurrentInstances(t *testing.T) {
+	first := breakingRepo(t)
+	second := newRepo(t,
+		map[string]string{"bar.py": "def bar(a):\n    pass\n"},
+		map[string]string{"bar.py": "def bar():\n    pass\n"})
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]FileResult, 2)
+	for i, dir := range []string{first, second} {
+		wg.Add(1)
+		go func(i int, dir string) {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				b, err := Init(dir, "start", "HEAD", "none.json")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if results[i], err = b.Analyze(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i, dir)
+	}
+	wg.Wait()
+
+	if 1 != len(results[0]) || "foo.go" != results[0][0].Filename {
+		t.Errorf("Expected results of foo.go, got %v", results[0])
+	}
+	if 1 != len(results[1]) || "bar.py" != results[1][0].Filename {
+		t.Errorf("Expected results of bar.py, got %v", results[1])
+	}
+	if after, _ := os.Getwd(); cwd != after {
+		t.Errorf("Expected working directory %q, got %q", cwd, after)
+	}
+}
+
+func TestIgnoredFiles(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{
+			"check-break.json": `{"excluded": {"glob": ["gen/**"]}}`,
+			"README.md":        "# Foo\n",
+			"gen/foo.go":       "package gen\n\nfunc Foo(a int) {\n}\n",
+			"foo_test.go":      "package foo\n\nfunc TestFoo(a int) {\n}\n",
+		},
+		map[string]string{
+			"README.md":   "# Bar\n",
+			"gen/foo.go":  "package gen\n\nfunc Foo() {\n}\n",
+			"foo_test.go": "package foo\n\nfunc TestFoo() {\n}\n",
+		})
+	b, err := Init(dir, "start", "HEAD", "check-break.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := b.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []IgnoredFile{
+		{Filename: "README.md", Reason: "unsupported extension"},
+		{Filename: "foo_test.go", Reason: "test file"},
+		{Filename: "gen/foo.go", Reason: "excluded"},
+	}
+	if ignored := report.IgnoredFiles(); !reflect.DeepEqual(expected, ignored) {
+		t.Errorf("Expected %v, got %v", expected, ignored)
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{
+			"check-break.json": `{"excluded": {"glob": ["gen/**"]}}`,
+			"foo.go":           "package foo\n\nfunc Foo(a int) {\n}\n",
+			"gen/foo.go":       "package gen\n\nfunc Foo(a int) {\n}\n",
+			"notes.txt":        "foo\n",
+		},
+		map[string]string{
+			"foo.go":     "package foo\n\nfunc Foo(a int, b int) {\n}\n",
+			"gen/foo.go": "package gen\n\nfunc Foo() {\n}\n",
+			"notes.txt":  "bar\n",
+			"bar.py":     "def bar():\n    pass\n",
+		})
+	b, err := Init(dir, "start", "HEAD", "check-break.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := b.ListFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Added files can't break anything, thus aren't listed
+	expected := &FileList{
+		Analysable: []string{"foo.go"},
+		Ignored: []IgnoredFile{
+			{Filename: "notes.txt", Reason: "unsupported extension"},
+			{Filename: "gen/foo.go", Reason: "excluded"},
+		},
+	}
+	if !reflect.DeepEqual(expected, list) {
+		t.Errorf("Expected %v, got %v", expected, list)
+	}
+}
+
+func TestAnalyzeFiles(t *testing.T) {
+	b, err := Init(manyFilesRepo(t, 3), "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		paths    []string
+		expected []string
+	}{
+		{"file", []string{"pkg1/foo.go"}, []string{"pkg1/foo.go"}},
+		{"directory", []string{"pkg2/"}, []string{"pkg2/foo.go"}},
+		{"unchanged", []string{"w1.json", "other/"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := b.AnalyzeFiles(tt.paths)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filenames := make([]string, 0)
+			for _, result := range results {
+				filenames = append(filenames, result.Filename)
+			}
+			if !reflect.DeepEqual(tt.expected, filenames) {
+				t.Errorf("Expected %q, got %q", tt.expected, filenames)
+			}
+		})
+	}
+}
+
+func TestAnalyzeContextCancelled(t *testing.T) {
+	dir := manyFilesRepo(t, 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls int32
+	runner := cancellingRunner{runner: execRunner{dir: dir}, cancel: cancel, calls: &calls}
+	b, err := InitWithRunner(dir, "start", "HEAD", "w1.json", runner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Init ran git already, analysis is cancelled a few commands later
+	runner.after = int(atomic.LoadInt32(&calls)) + 3
+	b.git = runner
+
+	results, err := b.AnalyzeContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %q, got %v", context.Canceled, err)
+	}
+	if results != nil {
+		t.Errorf("Expected no results, got %v", results)
+	}
+}
+
+func TestAnalyzeContextDeadline(t *testing.T) {
+	dir := breakingRepo(t)
+	b, err := Init(dir, "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	if _, err := b.AnalyzeContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected %q, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func BenchmarkReport(b *testing.B) {
+	dir := manyFilesRepo(b, 50)
+	checker, err := Init(dir, "start", "HEAD", "w8.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := checker.Report(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestCountByLanguage(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{
+			"a.go":  "package foo\n\nfunc Foo(a int, b int) {\n}\n\nfunc Bar() {\n}\n",
+			"b.go":  "package foo\n\nfunc Baz(a int) {\n}\n",
+			"c.php": "<?php\nfunction foo($a) {\n}\n",
+			"d.py":  "def foo(a):\n    pass\n",
+		},
+		map[string]string{
+			"a.go":  "package foo\n\nfunc Foo(a int) {\n}\n",
+			"b.go":  "package foo\n\nfunc Baz() {\n}\n",
+			"c.php": "<?php\nfunction foo() {\n}\n",
+			"d.py":  "def foo(a, b=1):\n    pass\n",
+		})
+	b, err := Init(dir, "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := b.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int{"go": 3, "php": 1}
+	if counts := report.CountByLanguage(); !reflect.DeepEqual(expected, counts) {
+		t.Errorf("Expected %v, got %v", expected, counts)
+	}
+	if summary := report.LanguagesSummary(); "go: 3, php: 1" != summary {
+		t.Errorf("Expected %q, got %q", "go: 3, php: 1", summary)
+	}
+}
+
+func TestSortReports(t *testing.T) {
+	expected := []FileReport{
+		{filename: "a.go", methods: []method{
+			{commonFactor: "func Bar(", before: "func Bar() {"},
+			{commonFactor: "func Foo(", before: "func Foo(a int) {", after: "func Foo() {"},
+			{commonFactor: "func Foo(", before: "func Foo(a int) {", after: "func Foo(a string) {"},
+		}},
+		{filename: "b/a.php", methods: []method{{commonFactor: "function foo(", before: "function foo($a) {"}}},
+		{filename: "b/b.go", methods: []method{{commonFactor: "func Baz(", before: "func Baz() {"}}},
+	}
+	random := rand.New(rand.NewSource(1))
+	for run := 0; run < 10; run++ {
+		shuffled := make([]FileReport, len(expected))
+		for i, fr := range expected {
+			shuffled[i] = FileReport{filename: fr.filename, methods: append([]method(nil), fr.methods...)}
+			random.Shuffle(len(shuffled[i].methods), func(a, b int) {
+				shuffled[i].methods[a], shuffled[i].methods[b] = shuffled[i].methods[b], shuffled[i].methods[a]
+			})
+		}
+		random.Shuffle(len(shuffled), func(a, b int) {
+			shuffled[a], shuffled[b] = shuffled[b], shuffled[a]
+		})
+
+		sortReports(shuffled)
+		if !reflect.DeepEqual(expected, shuffled) {
+			t.Fatalf("Expected %v, got %v", expected, shuffled)
+		}
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	b, err := Init(breakingRepo(t), "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := b.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	text := func() string {
+		var output strings.Builder
+		for _, fr := range report.Supported {
+			output.WriteString(fr.Report() + fr.Summary())
+		}
+		for _, group := range report.Groups() {
+			output.WriteString(group.Report())
+		}
+		return output.String()
+	}
+
+	color.NoColor = false
+	if colored := text(); !strings.Contains(colored, "\x1b[") {
+		t.Errorf("Expected colors, got %q", colored)
+	}
+	color.NoColor = true
+	if plain := text(); strings.Contains(plain, "\x1b[") {
+		t.Errorf("Expected no ANSI code, got %q", plain)
+	}
+	// Machine formats never have colors
+	color.NoColor = false
+	output, err := report.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(output), "\x1b[") || strings.Contains(string(output), "\\u001b[") {
+		t.Errorf("Expected no ANSI code, got %s", output)
+	}
+}
+
+func TestSummaryMatchesDetails(t *testing.T) {
+	color.NoColor = true
+	b, err := Init(manyFilesRepo(t, 3), "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := b.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := b.Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != len(report.Supported) {
+		t.Fatalf("Expected %d files, got %d", len(results), len(report.Supported))
+	}
+	for i, fr := range report.Supported {
+		if len(results[i].Breaks) != fr.Count() {
+			t.Errorf("Expected %d breaks for %s, got %d", len(results[i].Breaks), results[i].Filename, fr.Count())
+		}
+		expected := fmt.Sprintf(">> %s : %d potential(s) break(s)", results[i].Filename, len(results[i].Breaks))
+		if summary := fr.Summary(); expected != summary {
+			t.Errorf("Expected %s, got %s", expected, summary)
+		}
+	}
+}
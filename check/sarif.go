@@ -0,0 +1,144 @@
+package check
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// sarifLog is the root of a SARIF 2.1.0 document
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// ReportSARIF serializes potentials compatibility breaks in SARIF 2.1.0,
+// as understood by code scanning tools
+func (b *Break) ReportSARIF() ([]byte, error) {
+	report, err := b.Report()
+	if err != nil {
+		return nil, err
+	}
+
+	return report.SARIF()
+}
+
+// SARIF serializes potentials compatibility breaks of the report in SARIF
+// 2.1.0
+func (r *BreakReport) SARIF() ([]byte, error) {
+	rules := make([]sarifRule, 0)
+	known := make(map[string]bool)
+	sarifResults := make([]sarifResult, 0)
+	for _, fr := range r.Supported {
+		for _, m := range fr.methods {
+			id := ruleID(m.explanation)
+			explanation := m.displayed()
+			if !known[id] {
+				known[id] = true
+				rules = append(rules, sarifRule{
+					ID:               id,
+					ShortDescription: sarifMessage{Text: ruleName(explanation)},
+				})
+			}
+			message := explanation + " : " + m.before
+			if m.before == "" {
+				message += m.after
+			} else if m.after != "" {
+				message += " -> " + m.after
+			}
+			location := sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: fr.filename},
+			}
+			// Lines of deletions are in the old version, out of the analysed tree
+			if m.after != "" && m.line > 0 {
+				location.Region = &sarifRegion{StartLine: m.line}
+			}
+			sarifResults = append(sarifResults, sarifResult{
+				RuleID:    id,
+				Level:     "warning",
+				Message:   sarifMessage{Text: message},
+				Locations: []sarifLocation{{PhysicalLocation: location}},
+			})
+		}
+	}
+
+	return json.Marshal(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "check-break",
+						InformationURI: "https://github.com/Prytoegrian/check-break",
+						Rules:          rules,
+					},
+				},
+				Results: sarifResults,
+			},
+		},
+	})
+}
+
+// ruleID derives a stable identifier from an explanation
+// (`Deletion of parameter` -> `deletion-of-parameter`)
+func ruleID(explanation string) string {
+	return strings.Join(strings.Fields(strings.ToLower(ruleName(explanation))), "-")
+}
+
+// ruleName is an explanation without its details, between parenthesis
+func ruleName(explanation string) string {
+	if position := strings.Index(explanation, " ("); position != -1 {
+		return explanation[:position]
+	}
+
+	return explanation
+}
@@ -0,0 +1,58 @@
+package check
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestReportSARIF(t *testing.T) {
+	b, err := Init(breakingRepo(t), "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := b.ReportSARIF()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(output, &log); err != nil {
+		t.Fatal(err)
+	}
+	if "2.1.0" != log.Version || 1 != len(log.Runs) || "check-break" != log.Runs[0].Tool.Driver.Name {
+		t.Fatalf("Unexpected SARIF envelope %s", output)
+	}
+	rules := log.Runs[0].Tool.Driver.Rules
+	if 2 != len(rules) || "deletion-of-method" != rules[0].ID || "deletion-of-parameter" != rules[1].ID {
+		t.Errorf("Unexpected rules %v", rules)
+	}
+	results := log.Runs[0].Results
+	if 2 != len(results) {
+		t.Fatalf("Expected 2 results, got %v", results)
+	}
+	result := results[1]
+	if "deletion-of-parameter" != result.RuleID || "Deletion of parameter : func Foo(a int, b int) { -> func Foo(a int) {" != result.Message.Text {
+		t.Errorf("Unexpected result %v", result)
+	}
+	location := result.Locations[0].PhysicalLocation
+	if "foo.go" != location.ArtifactLocation.URI || nil == location.Region || 3 != location.Region.StartLine {
+		t.Errorf("Unexpected location %v", location)
+	}
+	// Deletions have no line in the analysed tree
+	if nil != results[0].Locations[0].PhysicalLocation.Region {
+		t.Errorf("Unexpected region of a deletion %v", results[0].Locations[0].PhysicalLocation.Region)
+	}
+}
+
+func TestRuleID(t *testing.T) {
+	tests := map[string]string{
+		"Deletion of parameter":                            "deletion-of-parameter",
+		"Mutability contract changed (&self -> &mut self)": "mutability-contract-changed",
+		"Made final/non-overridable":                       "made-final/non-overridable",
+	}
+	for explanation, expected := range tests {
+		if id := ruleID(explanation); expected != id {
+			t.Errorf("Expected %s for %s, got %s", expected, explanation, id)
+		}
+	}
+}
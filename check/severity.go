@@ -0,0 +1,179 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity qualifies how likely a potential compatibility break affects consumers
+type Severity int
+
+const (
+	// Soft breaks may not affect consumers, depending on their usage
+	Soft Severity = iota
+	// Hard breaks affect every consumer
+	Hard
+)
+
+func (s Severity) String() string {
+	if Hard == s {
+		return "hard"
+	}
+
+	return "soft"
+}
+
+// parseSeverity reads a severity from its name
+func parseSeverity(name string) (Severity, error) {
+	switch name {
+	case "soft":
+		return Soft, nil
+	case "hard":
+		return Hard, nil
+	}
+
+	return Soft, fmt.Errorf("Unknown severity %s, expecting soft or hard", name)
+}
+
+// softExplanations are explanations of changes which may not affect
+// consumers, whatever the langage:
+//   - Unknown signature change : the nature of the change isn't known
+//   - Type parameters changed : inferred type arguments may still fit
+//   - Default value changed : only callers relying on the default are concerned
+//   - Public constant changed : only a change of behaviour
+//
+// Every other explanation is hard, but the arity ones in loose arity langages
+var softExplanations = map[string]bool{
+	"Unknown signature change": true,
+	"Type parameters changed":  true,
+	"Default value changed":    true,
+	"Public constant changed":  true,
+}
+
+// arityExplanations are explanations of a change of the number of
+// parameters, soft in loose arity langages
+var arityExplanations = map[string]bool{
+	"Adding a parameter without default value": true,
+	"Deletion of parameter":                    true,
+}
+
+// looseArityLangages are langages where calls with missing or extra
+// arguments still run, missing ones being undefined (javascript, shell)
+var looseArityLangages = map[string]bool{"js": true, "mjs": true, "cjs": true, "jsx": true, "sh": true}
+
+// severityOf classifies an explanation given by explainedChanges for a type
+// of file, its details between parenthesis aside
+func severityOf(explanation string, typeFile string) Severity {
+	name := ruleName(explanation)
+	if softExplanations[name] || (arityExplanations[name] && looseArityLangages[typeFile]) {
+		return Soft
+	}
+
+	return Hard
+}
+
+// Count is the number of potentials compatibility breaks at least as severe as minimum
+func (r *BreakReport) Count(minimum Severity) int {
+	count := 0
+	for _, fr := range r.Supported {
+		for _, m := range fr.methods {
+			if m.severity >= minimum {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// Offenders describes potentials compatibility breaks at least as severe as
+// minimum, one per break (`file : explanation : signature`)
+func (r *BreakReport) Offenders(minimum Severity) []string {
+	return r.offenders(func(m method) bool {
+		return m.severity >= minimum
+	})
+}
+
+// offenders describes potentials compatibility breaks kept by keep
+func (r *BreakReport) offenders(keep func(method) bool) []string {
+	offenders := make([]string, 0)
+	for _, fr := range r.Supported {
+		for _, m := range fr.methods {
+			if !keep(m) {
+				continue
+			}
+			signature := m.before
+			if "" == signature {
+				signature = m.after
+			}
+			offenders = append(offenders, fmt.Sprintf("%s : %s : %s", fr.filename, m.displayed(), signature))
+		}
+	}
+
+	return offenders
+}
+
+// FailOn restricts failures to some explanations (`Deletion of method`) or
+// their message IDs (`deletion-of-method`), other breaks being reported
+// without failing. Hard breaks fail if none are given
+func (b *Break) FailOn(explanations []string) error {
+	failOn := make(map[string]bool, len(explanations))
+	for _, explanation := range explanations {
+		id := ruleID(strings.TrimSpace(explanation))
+		if "" == id {
+			continue
+		}
+		if !isMessageID(id) {
+			return fmt.Errorf("Unknown explanation %s", strings.TrimSpace(explanation))
+		}
+		failOn[id] = true
+	}
+	b.failOn = failOn
+
+	return nil
+}
+
+// fails tells if a break fails the analysis
+func (b *Break) fails(m method) bool {
+	if 0 == len(b.failOn) {
+		return Hard == m.severity
+	}
+
+	return b.failOn[ruleID(m.explanation)]
+}
+
+// Failures is the number of potentials compatibility breaks failing the
+// analysis, hard ones unless restricted by FailOn
+func (r *BreakReport) Failures() int {
+	return len(r.FailingOffenders())
+}
+
+// FailingOffenders describes potentials compatibility breaks failing the
+// analysis, as Offenders does
+func (r *BreakReport) FailingOffenders() []string {
+	return r.offenders(func(m method) bool {
+		return m.failing
+	})
+}
+
+// FailureCount is the number of potentials compatibility breaks failing the
+// analysis, for callers to decide of a failure
+func (b *Break) FailureCount() (int, error) {
+	report, err := b.Report()
+	if err != nil {
+		return 0, err
+	}
+
+	return report.Failures(), nil
+}
+
+// BreakCount is the number of potentials compatibility breaks at least as
+// severe as minimum, for callers to decide of a failure
+func (b *Break) BreakCount(minimum Severity) (int, error) {
+	report, err := b.Report()
+	if err != nil {
+		return 0, err
+	}
+
+	return report.Count(minimum), nil
+}
@@ -0,0 +1,236 @@
+package check
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSeverityOf(t *testing.T) {
+	tests := []struct {
+		explanation string
+		typeFile    string
+		expected    Severity
+	}{
+		{"Deletion of method", "go", Hard},
+		{"Deletion of parameter", "go", Hard},
+		{"Adding a parameter without default value", "go", Hard},
+		{"Unknown signature change", "go", Soft},
+		{"Default value changed", "py", Soft},
+		{"Type parameters changed", "go", Soft},
+		{"Reduced visibility (public -> private)", "php", Hard},
+		{"Deletion of parameter", "js", Soft},
+		{"Adding a parameter without default value", "mjs", Soft},
+		{"Deletion of method", "js", Hard},
+		{"Adding a parameter without default value", "ts", Hard},
+	}
+	for _, tt := range tests {
+		if severity := severityOf(tt.explanation, tt.typeFile); tt.expected != severity {
+			t.Errorf("Expected %s for %s in %s, got %s", tt.expected, tt.explanation, tt.typeFile, severity)
+		}
+	}
+}
+
+func TestBreakCount(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{"foo.go": "package foo\n\nfunc Foo(a int) {\n}\n\nfunc Bar() {\n}\n\nfunc Map[T any](s []T) {\n}\n"},
+		map[string]string{"foo.go": "package foo\n\nfunc Foo() {\n}\n\nfunc Map[T comparable](s []T) {\n}\n"})
+	b, err := Init(dir, "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := b.BreakCount(Soft)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hard, err := b.BreakCount(Hard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if 3 != all || 2 != hard {
+		t.Errorf("Expected 3 breaks, 2 hard ones, got %d and %d", all, hard)
+	}
+	if failures, _ := b.FailureCount(); hard != failures {
+		t.Errorf("Expected hard breaks to fail, got %d failures", failures)
+	}
+}
+
+func TestMinSeverity(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{
+			"hard.json": `{"minSeverity": "hard"}`,
+			"soft.json": `{"minSeverity": "soft"}`,
+			"foo.go":    "package foo\n\nfunc Foo(a int) {\n}\n\nfunc Bar() {\n}\n\nfunc Map[T any](s []T) {\n}\n",
+		},
+		map[string]string{"foo.go": "package foo\n\nfunc Foo() {\n}\n\nfunc Map[T comparable](s []T) {\n}\n"})
+
+	tests := []struct {
+		config   string
+		expected []string
+	}{
+		{"none.json", []string{"Deletion of method", "Deletion of parameter", "Type parameters changed"}},
+		{"soft.json", []string{"Deletion of method", "Deletion of parameter", "Type parameters changed"}},
+		{"hard.json", []string{"Deletion of method", "Deletion of parameter"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.config, func(t *testing.T) {
+			assertExplanations(t, breaksOf(analyzed(t, dir, tt.config), "foo.go"), tt.expected...)
+		})
+	}
+}
+
+func TestInvalidMinSeverity(t *testing.T) {
+	dir := newRepo(t, map[string]string{"check-break.json": `{"minSeverity": "medium"}`}, map[string]string{})
+
+	if _, err := Init(dir, "start", "HEAD", "check-break.json"); err == nil || !strings.Contains(err.Error(), "Unknown severity medium") {
+		t.Errorf("Expected an unknown severity, got %v", err)
+	}
+}
+
+func TestFailOn(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{
+			"fail-on.json": `{"failOn": ["Deletion of method", "type-parameters-changed"]}`,
+			"foo.go":       "package foo\n\nfunc Foo(a int) {\n}\n\nfunc Bar() {\n}\n\nfunc Map[T any](s []T) {\n}\n",
+		},
+		map[string]string{"foo.go": "package foo\n\nfunc Foo() {\n}\n\nfunc Map[T comparable](s []T) {\n}\n"})
+
+	tests := []struct {
+		name     string
+		config   string
+		failOn   []string
+		expected int
+	}{
+		{"hard breaks by default", "none.json", nil, 2},
+		{"config", "fail-on.json", nil, 2},
+		{"option", "none.json", []string{"deletion-of-parameter"}, 1},
+		{"option over config", "fail-on.json", []string{" Deletion of parameter "}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Init(dir, "start", "HEAD", tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.failOn != nil {
+				if err := b.FailOn(tt.failOn); err != nil {
+					t.Fatal(err)
+				}
+			}
+			report, err := b.Report()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if failures := report.Failures(); tt.expected != failures {
+				t.Errorf("Expected %d failures, got %d", tt.expected, failures)
+			}
+			// Breaks not failing are still reported
+			if count := report.Count(Soft); 3 != count {
+				t.Errorf("Expected 3 breaks, got %d", count)
+			}
+		})
+	}
+}
+
+func TestUnknownFailOn(t *testing.T) {
+	dir := newRepo(t, map[string]string{"check-break.json": `{"failOn": ["Deletion of method", "Something else"]}`}, map[string]string{})
+
+	if _, err := Init(dir, "start", "HEAD", "check-break.json"); err == nil || "Unknown explanation Something else" != err.Error() {
+		t.Errorf("Expected an unknown explanation, got %v", err)
+	}
+	b, err := Init(dir, "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.FailOn([]string{"deletion-of-methods"}); err == nil || "Unknown explanation deletion-of-methods" != err.Error() {
+		t.Errorf("Expected an unknown message ID, got %v", err)
+	}
+	if err := b.FailOn([]string{"Reduced visibility (public -> private)", "mutability-contract-changed"}); err != nil {
+		t.Errorf("Expected explanations with details to be known, got %v", err)
+	}
+}
+
+func TestStagedFailingOffenders(t *testing.T) {
+	content := "package foo\n\nfunc Foo(a int) {\n}\n\nfunc Bar() {\n}\n\nfunc Map[T any](s []T) {\n}\n"
+	tests := []struct {
+		name     string
+		staged   string
+		failOn   []string
+		expected []string
+	}{
+		{"hard breaks", "package foo\n\nfunc Foo() {\n}\n\nfunc Map[T comparable](s []T) {\n}\n", nil, []string{
+			"foo.go : Deletion of method : func Bar() {",
+			"foo.go : Deletion of parameter : func Foo(a int) {",
+		}},
+		{"soft break only", "package foo\n\nfunc Foo(a int) {\n}\n\nfunc Bar() {\n}\n\nfunc Map[T comparable](s []T) {\n}\n", nil, []string{}},
+		{"restricted failures", "package foo\n\nfunc Foo() {\n}\n\nfunc Map[T comparable](s []T) {\n}\n", []string{"deletion-of-parameter"}, []string{
+			"foo.go : Deletion of parameter : func Foo(a int) {",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newRepo(t, map[string]string{"foo.go": content}, map[string]string{})
+			writeFiles(t, dir, map[string]string{"foo.go": tt.staged})
+			git(t, dir, "add", "foo.go")
+			b, err := Init(dir, "HEAD", Index, "none.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.failOn != nil {
+				if err := b.FailOn(tt.failOn); err != nil {
+					t.Fatal(err)
+				}
+			}
+			report, err := b.Report()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			offenders := report.FailingOffenders()
+			if !reflect.DeepEqual(tt.expected, offenders) {
+				t.Errorf("Expected %q, got %q", tt.expected, offenders)
+			}
+			if len(tt.expected) != report.Failures() {
+				t.Errorf("Expected %d failures, got %d", len(tt.expected), report.Failures())
+			}
+		})
+	}
+}
+
+func TestFailureCountIsQuiet(t *testing.T) {
+	dir := newRepo(t,
+		map[string]string{"foo.go": "package foo\n\nfunc Foo(a int) {\n}\n\nfunc Bar() {\n}\n"},
+		map[string]string{"foo.go": "package foo\n\nfunc Foo() {\n}\n"})
+	b, err := Init(dir, "start", "HEAD", "none.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = writer, writer
+
+	count, errCount := b.FailureCount()
+	os.Stdout, os.Stderr = stdout, stderr
+	writer.Close()
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if errCount != nil {
+		t.Fatal(errCount)
+	}
+	if 2 != count {
+		t.Errorf("Expected 2 failures, got %d", count)
+	}
+	if 0 != len(output) {
+		t.Errorf("Expected no output, got %q", output)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/fatih/color"
 	"github.com/prytoegrian/check-break/check"
 )
 
@@ -14,27 +15,182 @@ func main() {
 	path := flag.String("p", "", "Path to analyse (optional)")
 	startingPoint := flag.String("s", "", "Git starting point")
 	endingPoint := flag.String("e", "", "Git ending point")
-	configFilename := flag.String("c", "cb-config.json", "Config filename, relative to analysed path (optional)")
+	configFilename := flag.String("c", "cb-config.json", "Config filename, looked for from analysed path up to the repository root (optional)")
+	fail := flag.Bool("fail", false, "Exit with status 1 if hard breaks are found (optional)")
+	failOn := flag.String("fail-on", "", "Comma-separated explanations failing instead of hard breaks, with -fail, -q or -pre-commit (optional)")
+	noColor := flag.Bool("no-color", false, "Disable colors, already off when output isn't a terminal or NO_COLOR is set (optional)")
+	quiet := flag.Bool("q", false, "Quiet, output nothing and exit with status 1 if hard breaks are found, 2 on error (optional)")
+	preCommit := flag.Bool("pre-commit", false, "Check staged changes against HEAD, failing with a concise message on hard breaks (optional)")
+	direct := flag.Bool("direct", false, "Compare ending point to starting point as is, rather than to their merge base (optional)")
+	stdin := flag.Bool("stdin", false, "Read changed files from stdin, in git diff --name-status format, rather than listing them (optional)")
+	progress := flag.Bool("progress", false, "Display the progress of the analysis on stderr (optional)")
+	list := flag.Bool("list", false, "Only list files to analyse and ignored ones (optional)")
+	format := flag.String("f", "text", "Output format : text, summary, grouped, json, sarif, markdown, junit, github (optional)")
 	flag.Parse()
+	if *quiet {
+		errorStatus = 2
+	}
+	if *noColor {
+		color.NoColor = true
+	}
+	if *preCommit {
+		defaultPoints(startingPoint, endingPoint)
+	}
 	if *startingPoint == "" {
-		log.Fatalln("Starting point is missing, use -h for details")
+		fatal("Starting point is missing, use -h for details")
 	}
 	if *endingPoint == "" {
-		log.Fatalln("Ending point is missing, use -h for details")
+		fatal("Ending point is missing, use -h for details")
 	}
 	b, errInit := check.Init(workingPath(*path), *startingPoint, *endingPoint, *configFilename)
 	if errInit != nil {
-		log.Fatal("Init failed : ", errInit)
+		fatal("Init failed : ", errInit)
+	}
+	if *direct {
+		b.CompareDirectly()
+	}
+	if *stdin {
+		if errList := b.ReadFileList(os.Stdin); errList != nil {
+			fatal("Invalid file list : ", errList)
+		}
+	}
+	if *progress {
+		b.OnProgress(displayProgress)
+	}
+	if *failOn != "" {
+		if errFailOn := b.FailOn(strings.Split(*failOn, ",")); errFailOn != nil {
+			fatal("Invalid -fail-on : ", errFailOn)
+		}
+	}
+	if *quiet {
+		exitQuietly(b)
+	}
+
+	if *list {
+		displayFiles(b)
+		return
+	}
+	if *preCommit {
+		checkStaged(b)
+		return
+	}
+
+	switch *format {
+	case "json", "sarif", "markdown", "junit", "github":
+		// A single analysis, serialized and counted
+		report, errReport := b.Report()
+		if errReport != nil {
+			fatal("Error during report construction : ", errReport)
+		}
+		displayRaw(serialized(report, *format))
+		exitOnBreaks(report, *fail)
+		return
 	}
 
 	displayTitle(b)
+	if b.SamePoints() {
+		fmt.Println("> No change, both points are the same commit")
+		return
+	}
 	report, errReport := b.Report()
 	if errReport != nil {
-		log.Fatal("Error during report construction : ", errReport)
+		fatal("Error during report construction : ", errReport)
+	}
+	switch *format {
+	case "summary":
+		displaySummary(report)
+	case "grouped":
+		displayGroups(report)
+	default:
+		displayBreaks(report)
 	}
-	displayBreaks(report)
+	displayLanguages(report)
 	displayIgnored(report)
 	displayExclusions(report)
+	exitOnBreaks(report, *fail)
+}
+
+// errorStatus is the exit status on errors, 2 in quiet mode to tell them from
+// breaks
+var errorStatus = 1
+
+// fatal logs an error and exits with the error status
+func fatal(v ...interface{}) {
+	log.Print(v...)
+	os.Exit(errorStatus)
+}
+
+// defaultPoints compares the index to HEAD, unless points are given
+func defaultPoints(startingPoint *string, endingPoint *string) {
+	if *startingPoint == "" {
+		*startingPoint = "HEAD"
+	}
+	if *endingPoint == "" {
+		*endingPoint = check.Index
+	}
+}
+
+// checkStaged lists failing breaks on stderr and exits with status 1 if any,
+// for a pre-commit hook to abort the commit
+func checkStaged(b *check.Break) {
+	report, err := b.Report()
+	if err != nil {
+		fatal("Error during report construction : ", err)
+	}
+	offenders := report.FailingOffenders()
+	if 0 == len(offenders) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "check-break : %d compatibility break(s) staged, commit aborted\n", len(offenders))
+	for _, offender := range offenders {
+		fmt.Fprintln(os.Stderr, ">>", offender)
+	}
+	os.Exit(1)
+}
+
+// exitQuietly exits with status 1 if failing breaks are found, 2 if the
+// analysis fails, without any output but the error
+func exitQuietly(b *check.Break) {
+	count, err := b.FailureCount()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error during report construction :", err)
+		os.Exit(2)
+	}
+	if count > 0 {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func exitOnBreaks(report *check.BreakReport, fail bool) {
+	if fail && report.Failures() > 0 {
+		os.Exit(1)
+	}
+}
+
+// serialized serializes a report in a machine readable format
+func serialized(report *check.BreakReport, format string) ([]byte, error) {
+	switch format {
+	case "sarif":
+		return report.SARIF()
+	case "markdown":
+		return report.Markdown()
+	case "junit":
+		return report.JUnit()
+	case "github":
+		return report.GitHub()
+	}
+
+	return report.JSON()
+}
+
+// displayProgress displays the count of files processed on stderr, on a single
+// line rewritten each time
+func displayProgress(filename string, processed int, total int) {
+	fmt.Fprintf(os.Stderr, "\r> Analysing files : %d/%d", processed, total)
+	if processed == total {
+		fmt.Fprintln(os.Stderr)
+	}
 }
 
 func workingPath(userPath string) string {
@@ -43,11 +199,18 @@ func workingPath(userPath string) string {
 	}
 	path, err := os.Getwd()
 	if err != nil {
-		log.Fatalln(err, path)
+		fatal(err, " ", path)
 	}
 	return strings.TrimSpace(path)
 }
 
+func displayRaw(report []byte, err error) {
+	if err != nil {
+		fatal("Error during report construction : ", err)
+	}
+	fmt.Println(string(report))
+}
+
 func displayTitle(b *check.Break) {
 	fmt.Println("(For details, please consult https://github.com/Prytoegrian/check-break#what-is-a-compatibility-break-)")
 	if !b.HasConfiguration() {
@@ -70,6 +233,55 @@ func displayBreaks(report *check.BreakReport) {
 	}
 }
 
+func displaySummary(report *check.BreakReport) {
+	if 0 == len(report.Supported) {
+		fmt.Println("> No compatibility break")
+		fmt.Println()
+	} else {
+		fmt.Println("> Potentials compatibility breaks")
+		for _, fileReport := range report.Supported {
+			fmt.Println(fileReport.Summary())
+		}
+		fmt.Println()
+	}
+}
+
+func displayGroups(report *check.BreakReport) {
+	if 0 == len(report.Supported) {
+		fmt.Println("> No compatibility break")
+		fmt.Println()
+	} else {
+		fmt.Println("> Potentials compatibility breaks")
+		for _, group := range report.Groups() {
+			fmt.Println(group.Report())
+		}
+		fmt.Println()
+	}
+}
+
+func displayLanguages(report *check.BreakReport) {
+	if 0 != len(report.Supported) {
+		fmt.Println("> Breaks by language :", report.LanguagesSummary())
+	}
+}
+
+func displayFiles(b *check.Break) {
+	files, err := b.ListFiles()
+	if err != nil {
+		fatal("Error during files listing : ", err)
+	}
+	fmt.Println("> Files to analyse :")
+	for _, f := range files.Analysable {
+		fmt.Println(">>", f)
+	}
+	if 0 != len(files.Ignored) {
+		fmt.Println("\n> Ignored files :")
+		for _, f := range files.Ignored {
+			fmt.Printf(">> %s (%s)\n", f.Filename, f.Reason)
+		}
+	}
+}
+
 func displayIgnored(report *check.BreakReport) {
 	if 0 != len(report.Ignored) {
 		fmt.Println("> Unsupported files :")
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain runs main rather than tests when asked to, for tests to check its
+// exit status
+func TestMain(m *testing.M) {
+	if "1" == os.Getenv("CHECK_BREAK_MAIN") {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// exitStatus runs main with args, stdin as standard input, and returns its
+// exit status
+func exitStatus(t *testing.T, stdin string, args ...string) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "CHECK_BREAK_MAIN=1")
+	cmd.Stdin = strings.NewReader(stdin)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return 0
+}
+
+// newRepo creates a git repository with a single commit
+func newRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "foo.go"), []byte("package foo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "foo.go"},
+		{"-c", "user.name=check-break", "-c", "user.email=check-break@example.com", "commit", "-q", "-m", "start"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v : %s", args, out)
+		}
+	}
+
+	return dir
+}
+
+func TestQuietErrorStatus(t *testing.T) {
+	dir := newRepo(t)
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+	}{
+		{"missing starting point", "", []string{"-q", "-p", dir, "-e", "HEAD"}},
+		{"init failure", "", []string{"-q", "-p", filepath.Join(dir, "missing"), "-s", "HEAD", "-e", "HEAD"}},
+		{"unknown point", "", []string{"-q", "-p", dir, "-s", "v9.9.9", "-e", "HEAD"}},
+		{"invalid file list", "M foo.go\n", []string{"-q", "-stdin", "-p", dir, "-s", "HEAD", "-e", "HEAD"}},
+		{"unknown failing explanation", "", []string{"-q", "-fail-on", "Something else", "-p", dir, "-s", "HEAD", "-e", "HEAD"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := exitStatus(t, tt.stdin, tt.args...); 2 != status {
+				t.Errorf("Expected status 2, got %d", status)
+			}
+		})
+	}
+	if status := exitStatus(t, "", "-p", dir, "-s", "v9.9.9", "-e", "HEAD"); 1 != status {
+		t.Errorf("Expected status 1 without -q, got %d", status)
+	}
+}